@@ -1,6 +1,10 @@
 package api
 
 import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"github.com/auth0/go-auth0/authentication/oauth"
 	"github.com/spf13/viper"
 	"github.com/stevezaluk/mtgjson-sdk-client/auth"
 	"github.com/stevezaluk/mtgjson-sdk-client/card"
@@ -8,7 +12,10 @@ import (
 	"github.com/stevezaluk/mtgjson-sdk-client/deck"
 	"github.com/stevezaluk/mtgjson-sdk-client/set"
 	"github.com/stevezaluk/mtgjson-sdk-client/user"
+	"net"
+	"net/url"
 	"strconv"
+	"strings"
 )
 
 /*
@@ -18,55 +25,227 @@ type MtgjsonAPI struct {
 	// client - A pointer to the client.HTTPClient structure that is used for HTTP requests
 	client *client.HTTPClient
 
-	// Card - A pointer to the CardAPI namespace, used for making HTTP requests to the /card endpoint
-	Card *card.CardAPI
+	// baseUrl - The base URL of the API that each namespace appends its endpoint to
+	baseUrl string
 
-	// Deck - A pointer to the DeckAPI namespace, used for making HTTP requests to the /deck endpoint
-	Deck *deck.DeckAPI
+	// Card - The card namespace, used for making HTTP requests to the /card endpoint. This can be replaced
+	// with any CardService, for example a mock in unit tests
+	Card CardService
 
-	// Set - A pointer to the SetAPI namespace, used for making HTTP requests to the /set endpoint
-	Set *set.SetAPI
+	// Deck - The deck namespace, used for making HTTP requests to the /deck endpoint
+	Deck DeckService
 
-	// Auth - A pointer to the AuthAPI namespace, used for making HTTP requests to the /login and /register endpoints
-	Auth *auth.AuthAPI
+	// Set - The set namespace, used for making HTTP requests to the /set endpoint
+	Set SetService
 
-	// User - A pointer to the UserAPI namespace, used for making HTTP requests to the /user endpoint
-	User *user.UserAPI
+	// Auth - The auth namespace, used for making HTTP requests to the /login and /register endpoints
+	Auth AuthService
+
+	// User - The user namespace, used for making HTTP requests to the /user endpoint
+	User UserService
+
+	// tokenRefresh - True if EnableTokenRefresh has been called
+	tokenRefresh bool
 }
 
 /*
 New - Construct a new MtgjsonAPI structure using a hostname and port. If useSSL is set
-to true then the protocol will be switched to HTTPS
+to true then the protocol will be switched to HTTPS. If port is 0 then the default port
+for the protocol is used. IPv6 literals may be passed as the hostname with or without
+brackets. The options passed in the parameter are used for constructing the client.HTTPClient
 */
-func New(hostname string, port int, useSSL bool) *MtgjsonAPI {
-	httpClient := client.New()
+func New(hostname string, port int, useSSL bool, opts ...client.Option) (*MtgjsonAPI, error) {
+	if port < 0 || port > 65535 {
+		return nil, fmt.Errorf("%w: port %d is out of range", client.ErrInvalidBaseURL, port)
+	}
 
-	protocol := "http://"
+	protocol := "http"
 	if useSSL {
-		protocol = "https://"
+		protocol = "https"
+	}
+
+	host := strings.TrimSuffix(strings.TrimPrefix(hostname, "["), "]")
+	if port != 0 {
+		host = net.JoinHostPort(host, strconv.Itoa(port))
+	} else if strings.Contains(host, ":") { // IPv6 literals must be wrapped in brackets
+		host = "[" + host + "]"
+	}
+
+	return NewFromURL((&url.URL{Scheme: protocol, Host: host}).String(), opts...)
+}
+
+/*
+NewFromURL - Construct a new MtgjsonAPI structure using the full base URL of the API,
+including any path prefix the server is mounted under (for example
+https://example.com/mtgjson/v1). Returns client.ErrInvalidBaseURL if the URL is malformed.
+The HTTP client is constructed with the options passed in the parameter. Token refresh is
+disabled until EnableTokenRefresh is called
+*/
+func NewFromURL(baseUrl string, opts ...client.Option) (*MtgjsonAPI, error) {
+	parsed, err := client.ParseBaseURL(baseUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	baseUrl = strings.TrimSuffix(parsed.String(), "/")
+
+	httpClient, err := client.New(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	api := &MtgjsonAPI{
+		client:  httpClient,
+		baseUrl: baseUrl,
+	}
+
+	cardApi, err := card.New(baseUrl, httpClient)
+	if err != nil {
+		return nil, err
+	}
+
+	deckApi, err := deck.New(baseUrl, httpClient)
+	if err != nil {
+		return nil, err
+	}
+
+	setApi, err := set.New(baseUrl, httpClient)
+	if err != nil {
+		return nil, err
+	}
+
+	authApi, err := auth.New(baseUrl, httpClient)
+	if err != nil {
+		return nil, err
+	}
+
+	userApi, err := user.New(baseUrl, httpClient)
+	if err != nil {
+		return nil, err
+	}
+
+	api.Card, api.Deck, api.Set, api.Auth, api.User = cardApi, deckApi, setApi, authApi, userApi
+
+	return api, nil
+}
+
+/*
+EnableTokenRefresh - Configure the HTTP client to refresh its access token through AuthService.RefreshToken, both
+shortly before it expires and when a request is rejected with a 401. This requires a server that provides the POST
+/refresh endpoint, which the upstream MTGJSON API does not have yet, so it is disabled by default. Without it, an
+expired token must be replaced by logging in again
+*/
+func (api *MtgjsonAPI) EnableTokenRefresh() {
+	api.tokenRefresh = true
+
+	// resolved on each refresh so that replacing the Auth service also replaces how tokens are refreshed
+	api.client.SetTokenRefresher(func(ctx context.Context, refreshToken string) (*oauth.TokenSet, error) {
+		return api.Auth.RefreshToken(ctx, refreshToken)
+	})
+}
+
+/*
+FromConfig - Construct a new MtgjsonAPI structure using viper config values. If api.base_url
+is set it is used as is, otherwise the URL is built from api.hostname, api.port and
+api.use_ssl. The HTTP client is configured from the following keys, with the options passed
+in the parameter applied afterward so that they take precedence:
+
+  - api.timeout, api.user_agent, api.proxy and api.headers (a map of header names to values)
+  - api.tls.insecure_skip_verify and api.tls.server_name
+  - api.retry.max_attempts, api.retry.base_delay, api.retry.max_delay, api.retry.jitter,
+    api.retry.status_codes and api.retry.methods. Any key that is not set falls back to the
+    value from client.DefaultRetryPolicy
+  - api.token_refresh, which calls EnableTokenRefresh if true. Leave it unset against the upstream MTGJSON API,
+    which has no refresh endpoint, and log in again once the token expires
+
+The client is authenticated automatically using the credential chain returned from
+DefaultCredentialChain, with the providers passed in the parameter tried first. The token
+obtained is persisted to the file at api.token_path (client.DefaultTokenPath if not set) so
+that it can be reused by the next run. If no provider can authenticate the client, an error
+wrapping ErrNoCredentials is returned describing each provider that was tried
+*/
+func FromConfig(ctx context.Context, providers []CredentialProvider, opts ...client.Option) (*MtgjsonAPI, error) {
+	var api *MtgjsonAPI
+
+	opts = append(clientOptionsFromConfig(), opts...)
+
+	var err error
+	if viper.IsSet("api.base_url") {
+		api, err = NewFromURL(viper.GetString("api.base_url"), opts...)
+	} else {
+		api, err = New(
+			viper.GetString("api.hostname"),
+			viper.GetInt("api.port"),
+			viper.GetBool("api.use_ssl"),
+			opts...,
+		)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if viper.GetBool("api.token_refresh") {
+		api.EnableTokenRefresh()
+	}
+
+	store, err := client.NewFileTokenStore(viper.GetString("api.token_path"))
+	if err != nil {
+		return nil, err
+	}
+
+	err = api.Authenticate(ctx, DefaultCredentialChain(store, providers...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	err = api.Client().SetTokenStore(store)
+	if err != nil {
+		return nil, err
+	}
+
+	return api, nil
+}
+
+/*
+retryPolicyFromConfig - Build a client.RetryPolicy from the viper config values under api.retry
+*/
+func retryPolicyFromConfig() *client.RetryPolicy {
+	policy := client.DefaultRetryPolicy()
+
+	if viper.IsSet("api.retry.max_attempts") {
+		policy.MaxAttempts = viper.GetInt("api.retry.max_attempts")
+	}
+
+	if viper.IsSet("api.retry.base_delay") {
+		policy.BaseDelay = viper.GetDuration("api.retry.base_delay")
+	}
+
+	if viper.IsSet("api.retry.max_delay") {
+		policy.MaxDelay = viper.GetDuration("api.retry.max_delay")
+	}
+
+	if viper.IsSet("api.retry.jitter") {
+		policy.Jitter = viper.GetFloat64("api.retry.jitter")
 	}
 
-	baseUrl := protocol + hostname + ":" + strconv.Itoa(port)
+	if viper.IsSet("api.retry.status_codes") {
+		policy.RetryableStatusCodes = viper.GetIntSlice("api.retry.status_codes")
+	}
 
-	return &MtgjsonAPI{
-		client: httpClient,
-		Card:   card.New(baseUrl, httpClient),
-		Deck:   deck.New(baseUrl, httpClient),
-		Set:    set.New(baseUrl, httpClient),
-		Auth:   auth.New(baseUrl, httpClient),
-		User:   user.New(baseUrl, httpClient),
+	if viper.IsSet("api.retry.methods") {
+		policy.RetryableMethods = nil
+		policy.AllowMethods(viper.GetStringSlice("api.retry.methods")...)
 	}
+
+	return policy
 }
 
 /*
-FromConfig - Construct a new MtgjsonAPI structure using viper config values
+BaseURL - Returns the base URL of the API that each namespace appends its endpoint to
 */
-func FromConfig() *MtgjsonAPI {
-	return New(
-		viper.GetString("api.hostname"),
-		viper.GetInt("api.port"),
-		viper.GetBool("api.use_ssl"),
-	)
+func (api *MtgjsonAPI) BaseURL() string {
+	return api.baseUrl
 }
 
 /*
@@ -79,13 +258,43 @@ func (api *MtgjsonAPI) Client() *client.HTTPClient {
 /*
 SetEmailPasswordAuth - Fetches a token for the client.HTTPClient to use in authenticated requests
 */
-func (api *MtgjsonAPI) SetEmailPasswordAuth(email string, password string) error {
-	tokenSet, err := api.Auth.Login(email, password)
+func (api *MtgjsonAPI) SetEmailPasswordAuth(ctx context.Context, email string, password string) error {
+	tokenSet, err := api.Auth.Login(ctx, email, password)
 	if err != nil {
 		return err
 	}
 
-	api.Client().SetBearerToken(tokenSet)
+	return api.Client().SetBearerToken(tokenSet)
+}
+
+/*
+clientOptionsFromConfig - Build the client.Option list for the HTTP client from the viper config values under api
+*/
+func clientOptionsFromConfig() []client.Option {
+	opts := []client.Option{client.WithRetryPolicy(retryPolicyFromConfig())}
+
+	if viper.IsSet("api.timeout") {
+		opts = append(opts, client.WithTimeout(viper.GetDuration("api.timeout")))
+	}
+
+	if viper.IsSet("api.user_agent") {
+		opts = append(opts, client.WithUserAgent(viper.GetString("api.user_agent")))
+	}
+
+	if viper.IsSet("api.proxy") {
+		opts = append(opts, client.WithProxy(viper.GetString("api.proxy")))
+	}
+
+	for key, value := range viper.GetStringMapString("api.headers") {
+		opts = append(opts, client.WithHeader(key, value))
+	}
+
+	if viper.IsSet("api.tls.insecure_skip_verify") || viper.IsSet("api.tls.server_name") {
+		opts = append(opts, client.WithTLSConfig(&tls.Config{
+			InsecureSkipVerify: viper.GetBool("api.tls.insecure_skip_verify"),
+			ServerName:         viper.GetString("api.tls.server_name"),
+		}))
+	}
 
-	return nil
+	return opts
 }
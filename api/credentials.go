@@ -0,0 +1,285 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/auth0/go-auth0/authentication/oauth"
+	"github.com/spf13/viper"
+	"github.com/stevezaluk/mtgjson-sdk-client/client"
+	"os"
+	"strings"
+)
+
+/*
+ErrNoCredentials - Returned by a CredentialProvider that has no credentials to offer, and by MtgjsonAPI.Authenticate
+when none of the providers in the chain were able to authenticate the client
+*/
+var ErrNoCredentials = errors.New("api: no credentials could be resolved")
+
+/*
+Credentials - The credentials resolved by a CredentialProvider. Either Token or Email and Password are set
+*/
+type Credentials struct {
+	// Email - The email address of the user, used for logging in with AuthAPI.Login
+	Email string
+
+	// Password - The password of the user, used for logging in with AuthAPI.Login
+	Password string
+
+	// Token - A token that can be used directly without logging in
+	Token *client.StoredToken
+}
+
+/*
+CredentialProvider - An interface for a single source of credentials in the chain used by MtgjsonAPI.Authenticate
+*/
+type CredentialProvider interface {
+	// Name - A short, human-readable name of the provider that is used in error messages
+	Name() string
+
+	// Retrieve - Resolve the credentials. Returns ErrNoCredentials if the provider has nothing to offer
+	Retrieve(ctx context.Context) (*Credentials, error)
+}
+
+/*
+StaticProvider - A CredentialProvider that returns credentials passed to it explicitly
+*/
+type StaticProvider struct {
+	// credentials - The credentials returned from Retrieve
+	credentials Credentials
+}
+
+/*
+NewStaticProvider - Create a new StaticProvider that logs in with the email address and password passed in the parameter
+*/
+func NewStaticProvider(email string, password string) *StaticProvider {
+	return &StaticProvider{credentials: Credentials{Email: email, Password: password}}
+}
+
+/*
+NewStaticTokenProvider - Create a new StaticProvider that authenticates with the token set passed in the parameter
+*/
+func NewStaticTokenProvider(token *oauth.TokenSet) *StaticProvider {
+	return &StaticProvider{credentials: Credentials{Token: &client.StoredToken{Token: token}}}
+}
+
+/*
+Name - Returns the name of the provider
+*/
+func (provider *StaticProvider) Name() string {
+	return "static"
+}
+
+/*
+Retrieve - Returns the credentials passed to the provider. Returns ErrNoCredentials if they are incomplete
+*/
+func (provider *StaticProvider) Retrieve(ctx context.Context) (*Credentials, error) {
+	return completeCredentials(provider.credentials.Email, provider.credentials.Password, provider.credentials.Token)
+}
+
+/*
+EnvProvider - A CredentialProvider that reads credentials from the MTGJSON_EMAIL and MTGJSON_PASSWORD, or the
+MTGJSON_TOKEN environment variables
+*/
+type EnvProvider struct{}
+
+/*
+NewEnvProvider - Create a new EnvProvider
+*/
+func NewEnvProvider() *EnvProvider {
+	return &EnvProvider{}
+}
+
+/*
+Name - Returns the name of the provider
+*/
+func (provider *EnvProvider) Name() string {
+	return "environment"
+}
+
+/*
+Retrieve - Read the credentials from the environment. Returns ErrNoCredentials if the variables are not set
+*/
+func (provider *EnvProvider) Retrieve(ctx context.Context) (*Credentials, error) {
+	return completeCredentials(
+		os.Getenv("MTGJSON_EMAIL"),
+		os.Getenv("MTGJSON_PASSWORD"),
+		accessToken(os.Getenv("MTGJSON_TOKEN")),
+	)
+}
+
+/*
+ConfigProvider - A CredentialProvider that reads credentials from the api.email and api.password, or the api.token
+viper config values
+*/
+type ConfigProvider struct{}
+
+/*
+NewConfigProvider - Create a new ConfigProvider
+*/
+func NewConfigProvider() *ConfigProvider {
+	return &ConfigProvider{}
+}
+
+/*
+Name - Returns the name of the provider
+*/
+func (provider *ConfigProvider) Name() string {
+	return "config"
+}
+
+/*
+Retrieve - Read the credentials from viper. Returns ErrNoCredentials if the keys are not set
+*/
+func (provider *ConfigProvider) Retrieve(ctx context.Context) (*Credentials, error) {
+	return completeCredentials(
+		viper.GetString("api.email"),
+		viper.GetString("api.password"),
+		accessToken(viper.GetString("api.token")),
+	)
+}
+
+/*
+TokenStoreProvider - A CredentialProvider that reuses a token that was persisted in a client.TokenStore by a
+previous run
+*/
+type TokenStoreProvider struct {
+	// store - The store the token is loaded from
+	store client.TokenStore
+}
+
+/*
+NewTokenStoreProvider - Create a new TokenStoreProvider that loads the token from the store passed in the parameter
+*/
+func NewTokenStoreProvider(store client.TokenStore) *TokenStoreProvider {
+	return &TokenStoreProvider{store: store}
+}
+
+/*
+Name - Returns the name of the provider
+*/
+func (provider *TokenStoreProvider) Name() string {
+	if fileStore, ok := provider.store.(*client.FileTokenStore); ok {
+		return "token file (" + fileStore.Path() + ")"
+	}
+
+	return "token store"
+}
+
+/*
+Retrieve - Load the token from the store. Returns ErrNoCredentials if no token was saved, or if the saved token has
+expired and cannot be refreshed
+*/
+func (provider *TokenStoreProvider) Retrieve(ctx context.Context) (*Credentials, error) {
+	stored, err := provider.store.Load()
+	if errors.Is(err, client.ErrNoStoredToken) {
+		return nil, ErrNoCredentials
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !stored.Usable() {
+		return nil, fmt.Errorf("%w: the saved token has expired", ErrNoCredentials)
+	}
+
+	return &Credentials{Token: stored}, nil
+}
+
+/*
+completeCredentials - Returns credentials using the token if one is available, otherwise the email and password.
+Returns ErrNoCredentials if neither are complete
+*/
+func completeCredentials(email string, password string, token *client.StoredToken) (*Credentials, error) {
+	if token != nil && token.Token != nil && token.Token.AccessToken != "" {
+		return &Credentials{Token: token}, nil
+	}
+
+	if email == "" || password == "" {
+		return nil, ErrNoCredentials
+	}
+
+	return &Credentials{Email: email, Password: password}, nil
+}
+
+/*
+accessToken - Wrap a raw access token in a client.StoredToken. Returns nil if the token is empty
+*/
+func accessToken(token string) *client.StoredToken {
+	if token == "" {
+		return nil
+	}
+
+	return &client.StoredToken{Token: &oauth.TokenSet{AccessToken: token, TokenType: "Bearer"}}
+}
+
+/*
+Authenticate - Resolve credentials from each provider in order and authenticate the client with the first one that
+succeeds. Tokens are used as is, while an email address and password are exchanged for a token with AuthAPI.Login.
+If no provider succeeds, an error wrapping ErrNoCredentials is returned that describes each provider that was tried
+*/
+func (api *MtgjsonAPI) Authenticate(ctx context.Context, providers ...CredentialProvider) error {
+	var tried []string
+
+	for _, provider := range providers {
+		err := api.authenticateWith(ctx, provider)
+		if err == nil {
+			return nil
+		}
+
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return err
+		}
+
+		reason := err.Error()
+		if err == ErrNoCredentials {
+			reason = "no credentials found"
+		}
+
+		tried = append(tried, provider.Name()+": "+reason)
+	}
+
+	if len(tried) == 0 {
+		return fmt.Errorf("%w: no credential providers were configured", ErrNoCredentials)
+	}
+
+	return fmt.Errorf("%w, tried [%s]", ErrNoCredentials, strings.Join(tried, "; "))
+}
+
+/*
+authenticateWith - Authenticate the client with the credentials resolved from a single provider
+*/
+func (api *MtgjsonAPI) authenticateWith(ctx context.Context, provider CredentialProvider) error {
+	credentials, err := provider.Retrieve(ctx)
+	if err != nil {
+		return err
+	}
+
+	if credentials.Token != nil {
+		if credentials.Token.Expired() && !api.tokenRefresh { // the token could only be used after a refresh
+			return fmt.Errorf("%w: the saved token has expired and token refresh is disabled", ErrNoCredentials)
+		}
+
+		return api.Client().SetStoredToken(credentials.Token)
+	}
+
+	return api.SetEmailPasswordAuth(ctx, credentials.Email, credentials.Password)
+}
+
+/*
+DefaultCredentialChain - Returns the providers used by FromConfig, in order: the providers passed in the
+parameter, the MTGJSON_* environment variables, the api.email/api.password or api.token viper config values,
+and finally the token persisted in the store
+*/
+func DefaultCredentialChain(store client.TokenStore, providers ...CredentialProvider) []CredentialProvider {
+	chain := append([]CredentialProvider{}, providers...)
+	chain = append(chain, NewEnvProvider(), NewConfigProvider())
+
+	if store != nil {
+		chain = append(chain, NewTokenStoreProvider(store))
+	}
+
+	return chain
+}
@@ -0,0 +1,103 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"github.com/auth0/go-auth0/authentication/oauth"
+	"github.com/stevezaluk/mtgjson-sdk-client/api"
+	"github.com/stevezaluk/mtgjson-sdk-client/client"
+	"github.com/stevezaluk/mtgjson-sdk-client/testserver"
+	"testing"
+	"time"
+)
+
+func TestAuthenticate(t *testing.T) {
+	server := testserver.New()
+	t.Cleanup(server.Close)
+
+	if err := server.AddUser("user@example.com", "user", "password123"); err != nil {
+		t.Fatal(err)
+	}
+
+	issued, err := server.IssueToken("user@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expired := client.NewMemoryTokenStore()
+	if err := expired.Save(&client.StoredToken{Token: issued, Expiry: time.Now().Add(-time.Minute)}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		env       map[string]string
+		providers []api.CredentialProvider
+		wantErr   error
+	}{
+		{name: "no providers", wantErr: api.ErrNoCredentials},
+		{name: "static email and password", providers: []api.CredentialProvider{api.NewStaticProvider("user@example.com", "password123")}},
+		{name: "static token", providers: []api.CredentialProvider{api.NewStaticTokenProvider(issued)}},
+		{
+			name:      "falls through a failed login",
+			providers: []api.CredentialProvider{api.NewStaticProvider("user@example.com", "wrong-password"), api.NewStaticTokenProvider(issued)},
+		},
+		{
+			name:      "environment email and password",
+			env:       map[string]string{"MTGJSON_EMAIL": "user@example.com", "MTGJSON_PASSWORD": "password123"},
+			providers: []api.CredentialProvider{api.NewEnvProvider()},
+		},
+		{
+			name:      "environment token",
+			env:       map[string]string{"MTGJSON_TOKEN": issued.AccessToken},
+			providers: []api.CredentialProvider{api.NewEnvProvider()},
+		},
+		{name: "empty environment", providers: []api.CredentialProvider{api.NewEnvProvider()}, wantErr: api.ErrNoCredentials},
+		{
+			name:      "expired token without refresh",
+			providers: []api.CredentialProvider{api.NewTokenStoreProvider(expired)},
+			wantErr:   api.ErrNoCredentials,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, name := range []string{"MTGJSON_EMAIL", "MTGJSON_PASSWORD", "MTGJSON_TOKEN"} {
+				t.Setenv(name, test.env[name])
+			}
+
+			mtgjson, err := api.NewFromURL(server.URL())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = mtgjson.Authenticate(context.Background(), test.providers...)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("Authenticate() error = %v, want %v", err, test.wantErr)
+			}
+
+			if test.wantErr != nil {
+				return
+			}
+
+			if _, err := mtgjson.User.GetUser(context.Background(), "user@example.com"); err != nil {
+				t.Errorf("GetUser() with the resolved credentials error = %v", err)
+			}
+		})
+	}
+}
+
+func TestAuthenticateStopsOnCancel(t *testing.T) {
+	mtgjson, err := api.NewFromURL("http://127.0.0.1:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = mtgjson.Authenticate(ctx, api.NewStaticProvider("user@example.com", "password123"), api.NewStaticTokenProvider(&oauth.TokenSet{AccessToken: "token"}))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Authenticate() error = %v, want context.Canceled", err)
+	}
+}
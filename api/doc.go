@@ -0,0 +1,11 @@
+/*
+Package api provides MtgjsonAPI, the entry point of the SDK, which holds a service for each namespace of the MTGJSON
+API and the client.HTTPClient that they share. It is constructed with New, NewFromURL or FromConfig.
+
+Access tokens are not refreshed by default. Refreshing requires a server that provides the POST /refresh endpoint,
+which the upstream MTGJSON API does not have yet, so once the access token expires every authenticated request fails
+with a 401 until the client logs in again, for example with SetEmailPasswordAuth or Authenticate. Against a server
+that provides the endpoint, call EnableTokenRefresh, or set api.token_refresh for FromConfig, to have the client
+refresh the token before it expires and retry a request once when it is rejected with a 401
+*/
+package api
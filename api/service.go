@@ -0,0 +1,171 @@
+package api
+
+import (
+	"context"
+	"github.com/auth0/go-auth0/authentication/oauth"
+	apiModels "github.com/stevezaluk/mtgjson-models/api"
+	cardModel "github.com/stevezaluk/mtgjson-models/card"
+	deckModel "github.com/stevezaluk/mtgjson-models/deck"
+	setModel "github.com/stevezaluk/mtgjson-models/set"
+	userModel "github.com/stevezaluk/mtgjson-models/user"
+	"github.com/stevezaluk/mtgjson-sdk-client/auth"
+	"github.com/stevezaluk/mtgjson-sdk-client/card"
+	"github.com/stevezaluk/mtgjson-sdk-client/client"
+	"github.com/stevezaluk/mtgjson-sdk-client/deck"
+	"github.com/stevezaluk/mtgjson-sdk-client/set"
+	"github.com/stevezaluk/mtgjson-sdk-client/user"
+	"iter"
+)
+
+/*
+CardService - An interface covering each operation of the card namespace. card.CardAPI implements this over HTTP
+*/
+type CardService interface {
+	// GetCard - Fetch a single card using its MTGJSONv4 UUID
+	GetCard(ctx context.Context, uuid string, owner string) (*cardModel.CardSet, error)
+
+	// GetCards - Fetch many cards using their MTGJSONv4 UUIDs, reporting the ones that could not be found
+	GetCards(ctx context.Context, uuids []string, owner string) ([]*cardModel.CardSet, map[string]error, error)
+
+	// IndexCards - Fetch a page of cards
+	IndexCards(ctx context.Context, limit int, offset int) (*[]*cardModel.CardSet, error)
+
+	// IterCards - Iterate over every card, fetching pages lazily
+	IterCards(ctx context.Context, pageSize int) iter.Seq2[*cardModel.CardSet, error]
+
+	// StreamCards - Iterate over a page of cards, decoding them one at a time
+	StreamCards(ctx context.Context, limit int, offset int) iter.Seq2[*cardModel.CardSet, error]
+
+	// Search - Iterate over the cards that match a query
+	Search(ctx context.Context, query *card.CardQuery, pageSize int) iter.Seq2[*cardModel.CardSet, error]
+
+	// NewCard - Insert a new card
+	NewCard(ctx context.Context, card *cardModel.CardSet, owner string) (*apiModels.APIResponse, error)
+
+	// UpdateCard - Replace a card, failing with client.ErrConflict if it was modified since it was fetched
+	UpdateCard(ctx context.Context, card *cardModel.CardSet, owner string) (*apiModels.APIResponse, error)
+
+	// PatchCard - Update the fields that differ between two copies of a card
+	PatchCard(ctx context.Context, original *cardModel.CardSet, updated *cardModel.CardSet, owner string) (*apiModels.APIResponse, error)
+
+	// UpsertCard - Create a card or update it if it differs, reporting which was done
+	UpsertCard(ctx context.Context, card *cardModel.CardSet, owner string) (client.UpsertAction, error)
+
+	// DeleteCard - Remove a card using its MTGJSONv4 UUID
+	DeleteCard(ctx context.Context, uuid string, owner string) (*apiModels.APIResponse, error)
+}
+
+/*
+DeckService - An interface covering each operation of the deck namespace. deck.DeckAPI implements this over HTTP
+*/
+type DeckService interface {
+	// GetDeck - Fetch a single deck using its deck code
+	GetDeck(ctx context.Context, code string, owner string) (*deckModel.Deck, error)
+
+	// NewDeck - Insert a new deck
+	NewDeck(ctx context.Context, deck *deckModel.Deck, owner string) (*apiModels.APIResponse, error)
+
+	// UpdateDeck - Replace a deck, failing with client.ErrConflict if it was modified since it was fetched
+	UpdateDeck(ctx context.Context, deck *deckModel.Deck, owner string) (*apiModels.APIResponse, error)
+
+	// PatchDeck - Update the fields that differ between two copies of a deck
+	PatchDeck(ctx context.Context, original *deckModel.Deck, updated *deckModel.Deck, owner string) (*apiModels.APIResponse, error)
+
+	// UpsertDeck - Create a deck or update it if it differs, reporting which was done
+	UpsertDeck(ctx context.Context, deck *deckModel.Deck, owner string) (client.UpsertAction, error)
+
+	// DeleteDeck - Remove a deck using its deck code
+	DeleteDeck(ctx context.Context, code string, owner string) (*apiModels.APIResponse, error)
+
+	// GetDeckContents - Fetch the cards referenced by the content IDs of a deck
+	GetDeckContents(ctx context.Context, code string, owner string) (*deckModel.DeckContents, error)
+
+	// AddCards - Add content IDs to each board of a deck
+	AddCards(ctx context.Context, code string, cards *deckModel.DeckContentIds, owner string) (*apiModels.APIResponse, error)
+
+	// RemoveCards - Remove content IDs from each board of a deck
+	RemoveCards(ctx context.Context, code string, cards *deckModel.DeckContentIds, owner string) (*apiModels.APIResponse, error)
+}
+
+/*
+SetService - An interface covering each operation of the set namespace. set.SetAPI implements this over HTTP
+*/
+type SetService interface {
+	// GetSet - Fetch a single set using its set code
+	GetSet(ctx context.Context, code string, owner string) (*setModel.Set, error)
+
+	// IndexSets - Fetch a page of sets
+	IndexSets(ctx context.Context, limit int, offset int) (*[]*setModel.Set, error)
+
+	// IterSets - Iterate over every set, fetching pages lazily
+	IterSets(ctx context.Context, pageSize int) iter.Seq2[*setModel.Set, error]
+
+	// NewSet - Insert a new set
+	NewSet(ctx context.Context, set *setModel.Set, owner string) (*apiModels.APIResponse, error)
+
+	// UpdateSet - Replace a set, failing with client.ErrConflict if it was modified since it was fetched
+	UpdateSet(ctx context.Context, set *setModel.Set, owner string) (*apiModels.APIResponse, error)
+
+	// PatchSet - Update the fields that differ between two copies of a set
+	PatchSet(ctx context.Context, original *setModel.Set, updated *setModel.Set, owner string) (*apiModels.APIResponse, error)
+
+	// UpsertSet - Create a set or update it if it differs, reporting which was done
+	UpsertSet(ctx context.Context, set *setModel.Set, owner string) (client.UpsertAction, error)
+
+	// DeleteSet - Remove a set using its set code
+	DeleteSet(ctx context.Context, code string, owner string) (*apiModels.APIResponse, error)
+
+	// GetSetContents - Fetch the cards in a set
+	GetSetContents(ctx context.Context, code string, owner string) (*[]*cardModel.CardSet, error)
+
+	// StreamSetContents - Iterate over the cards in a set, decoding them one at a time
+	StreamSetContents(ctx context.Context, code string, owner string) iter.Seq2[*cardModel.CardSet, error]
+
+	// AddCards - Add cards to a set using their MTGJSONv4 UUIDs
+	AddCards(ctx context.Context, code string, cards []string, owner string) (*apiModels.APIResponse, error)
+
+	// RemoveCards - Remove cards from a set using their MTGJSONv4 UUIDs
+	RemoveCards(ctx context.Context, code string, cards []string, owner string) (*apiModels.APIResponse, error)
+}
+
+/*
+AuthService - An interface covering each operation of the auth namespace. auth.AuthAPI implements this over HTTP
+*/
+type AuthService interface {
+	// Login - Exchange user credentials for a token set
+	Login(ctx context.Context, email string, password string) (*oauth.TokenSet, error)
+
+	// RefreshToken - Exchange a refresh token for a new token set
+	RefreshToken(ctx context.Context, refreshToken string) (*oauth.TokenSet, error)
+
+	// RegisterUser - Register a new user
+	RegisterUser(ctx context.Context, email string, username string, password string) (*apiModels.APIResponse, error)
+
+	// ResetUserPassword - Send a reset password email to a user
+	ResetUserPassword(ctx context.Context, email string) (*apiModels.APIResponse, error)
+}
+
+/*
+UserService - An interface covering each operation of the user namespace. user.UserAPI implements this over HTTP
+*/
+type UserService interface {
+	// GetUser - Fetch a user using their email address
+	GetUser(ctx context.Context, email string) (*userModel.User, error)
+
+	// DeactivateUser - Remove a user using their email address
+	DeactivateUser(ctx context.Context, email string) (*apiModels.APIResponse, error)
+}
+
+var (
+	_ CardService = (*card.CardAPI)(nil)
+	_ DeckService = (*deck.DeckAPI)(nil)
+	_ SetService  = (*set.SetAPI)(nil)
+	_ AuthService = (*auth.AuthAPI)(nil)
+	_ UserService = (*user.UserAPI)(nil)
+
+	_ Namespace = (*card.CardAPI)(nil)
+	_ Namespace = (*deck.DeckAPI)(nil)
+	_ Namespace = (*set.SetAPI)(nil)
+	_ Namespace = (*auth.AuthAPI)(nil)
+	_ Namespace = (*user.UserAPI)(nil)
+)
@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"errors"
 	"github.com/auth0/go-auth0/authentication/oauth"
 	apiModels "github.com/stevezaluk/mtgjson-models/api"
@@ -21,13 +22,19 @@ type AuthAPI struct {
 }
 
 /*
-New Create a new instance of the AuthAPI struct
+New Create a new instance of the AuthAPI struct. The base URL must be an absolute http(s) URL and may
+contain a path prefix, client.ErrInvalidBaseURL is returned if it is malformed
 */
-func New(baseUrl string, client *client.HTTPClient) *AuthAPI {
-	return &AuthAPI{
-		baseUrl: baseUrl,
-		client:  client,
+func New(baseUrl string, httpClient *client.HTTPClient) (*AuthAPI, error) {
+	endpoint, err := client.JoinURL(baseUrl)
+	if err != nil {
+		return nil, err
 	}
+
+	return &AuthAPI{
+		baseUrl: endpoint,
+		client:  httpClient,
+	}, nil
 }
 
 /*
@@ -44,112 +51,108 @@ func (api *AuthAPI) Client() *client.HTTPClient {
 	return api.client
 }
 
+/*
+ErrResetPasswordFailed - Returned when the API fails to send a reset password email
+*/
+var ErrResetPasswordFailed = errors.New("auth: failed to reset the user password") // this needs to be added as a named error to mtgjson-models
+
+/*
+loginErrors - Maps the status codes returned from POST /login to sentinel errors
+*/
+var loginErrors = client.ErrorMap{
+	http.StatusNotFound: {Default: sdkErrors.ErrNoUser},
+	http.StatusBadRequest: {Match: []error{
+		sdkErrors.ErrInvalidEmail,
+		sdkErrors.ErrInvalidPasswordLength,
+	}},
+	http.StatusInternalServerError: {Default: sdkErrors.ErrTokenInvalid},
+}
+
 /*
 Login Exchange user credentials for an oauth.TokenSet
 */
-func (api *AuthAPI) Login(email string, password string) (*oauth.TokenSet, error) {
-	request := api.client.BuildRequest(&oauth.TokenSet{}).
+func (api *AuthAPI) Login(ctx context.Context, email string, password string) (*oauth.TokenSet, error) {
+	request := api.client.BuildRequest(ctx).
 		SetBody(apiModels.LoginRequest{
 			Email:    email,
 			Password: password,
 		})
 
-	resp, err := request.Post(api.baseUrl + "/login")
-	if err != nil {
-		return nil, err
-	}
+	return client.Execute[oauth.TokenSet](request, http.MethodPost, api.baseUrl+"/login", loginErrors)
+}
 
-	if resp.Error() != nil { // were not returning the API response here to avoid having more than two responses
-		if resp.StatusCode() == http.StatusNotFound {
-			return nil, sdkErrors.ErrNoUser
-		}
+/*
+refreshRequest - The request body used for exchanging a refresh token for a new token set
+*/
+type refreshRequest struct {
+	// RefreshToken - The refresh token returned alongside the access token from Login
+	RefreshToken string `json:"refresh_token"`
+}
 
-		if resp.StatusCode() == http.StatusInternalServerError {
-			return nil, sdkErrors.ErrTokenInvalid
-		}
-	}
+/*
+refreshErrors - Maps the status codes returned from POST /refresh to sentinel errors
+*/
+var refreshErrors = client.ErrorMap{
+	http.StatusBadRequest:          {Default: sdkErrors.ErrTokenInvalid},
+	http.StatusInternalServerError: {Default: sdkErrors.ErrTokenInvalid},
+}
 
-	return resp.Result().(*oauth.TokenSet), nil
+/*
+RefreshToken Exchange a refresh token for a new oauth.TokenSet with POST /refresh. This satisfies
+client.RefreshFunc, and is used by the client.HTTPClient to refresh the access token once
+MtgjsonAPI.EnableTokenRefresh is called. The upstream MTGJSON API does not provide this endpoint yet
+*/
+func (api *AuthAPI) RefreshToken(ctx context.Context, refreshToken string) (*oauth.TokenSet, error) {
+	request := api.client.BuildRequest(ctx).
+		SetBody(refreshRequest{RefreshToken: refreshToken})
+
+	return client.Execute[oauth.TokenSet](request, http.MethodPost, api.baseUrl+"/refresh", refreshErrors)
+}
+
+/*
+registerErrors - Maps the status codes returned from POST /register to sentinel errors
+*/
+var registerErrors = client.ErrorMap{
+	http.StatusConflict: {Default: sdkErrors.ErrUserAlreadyExist}, // this needs to be added to the API
+	http.StatusBadRequest: {Match: []error{
+		sdkErrors.ErrInvalidPasswordLength,
+		sdkErrors.ErrInvalidEmail,
+	}},
+	http.StatusInternalServerError: {Default: sdkErrors.ErrFailedToRegisterUser},
 }
 
 /*
 RegisterUser Register a new user with Auth0 and store there user model within the MongoDB database
 */
-func (api *AuthAPI) RegisterUser(email string, username string, password string) (*apiModels.APIResponse, error) {
+func (api *AuthAPI) RegisterUser(ctx context.Context, email string, username string, password string) (*apiModels.APIResponse, error) {
 	if email == "" || username == "" || password == "" {
 		return nil, sdkErrors.ErrUserMissingId
 	}
 
-	request := api.client.BuildRequest(&apiModels.APIResponse{}).
+	request := api.client.BuildRequest(ctx).
 		SetBody(apiModels.RegisterRequest{
 			Username: username,
 			Email:    email,
 			Password: password,
 		})
 
-	resp, err := request.Post(api.baseUrl + "/register")
-	if err != nil {
-		return nil, err
-	}
-
-	if resp.Error() != nil {
-		errorResult := resp.Error().(*apiModels.APIResponse)
-		if resp.StatusCode() == http.StatusConflict { // this needs to be added to the API
-			return errorResult, sdkErrors.ErrUserAlreadyExist
-		}
-
-		if resp.StatusCode() == http.StatusBadRequest {
-			if errorResult.Err == sdkErrors.ErrInvalidPasswordLength.Error() {
-				return errorResult, sdkErrors.ErrInvalidPasswordLength
-			}
-
-			if errorResult.Err == sdkErrors.ErrInvalidEmail.Error() {
-				return errorResult, sdkErrors.ErrInvalidEmail
-			}
-		}
-
-		if resp.StatusCode() == http.StatusInternalServerError {
-			return errorResult, sdkErrors.ErrFailedToRegisterUser
-		}
-	}
+	return client.Execute[apiModels.APIResponse](request, http.MethodPost, api.baseUrl+"/register", registerErrors)
+}
 
-	return resp.Result().(*apiModels.APIResponse), nil
+/*
+resetPasswordErrors - Maps the status codes returned from GET /reset to sentinel errors
+*/
+var resetPasswordErrors = client.ErrorMap{
+	http.StatusBadRequest:          {Default: sdkErrors.ErrInvalidEmail},
+	http.StatusNotFound:            {Default: sdkErrors.ErrNoUser},
+	http.StatusInternalServerError: {Default: ErrResetPasswordFailed},
 }
 
 /*
 ResetUserPassword Send a reset password email to a specified user account
 */
-func (api *AuthAPI) ResetUserPassword(email string) (*apiModels.APIResponse, error) {
-	request := api.client.BuildRequest(&apiModels.APIResponse{}).SetQueryParam("email", email)
-
-	resp, err := request.Get(api.baseUrl + "/reset")
-	if err != nil {
-		return nil, err
-	}
-
-	if resp.Error() != nil {
-		errResult := resp.Error().(*apiModels.APIResponse)
-
-		if resp.StatusCode() == http.StatusUnauthorized {
-			return errResult, sdkErrors.ErrTokenInvalid
-		}
-
-		if resp.StatusCode() == http.StatusForbidden {
-			return errResult, sdkErrors.ErrInvalidPermissions
-		}
-
-		if resp.StatusCode() == http.StatusBadRequest {
-			return errResult, sdkErrors.ErrInvalidEmail
-		}
-
-		if resp.StatusCode() == http.StatusNotFound {
-			return errResult, sdkErrors.ErrNoUser
-		}
-
-		if resp.StatusCode() == http.StatusInternalServerError {
-			return errResult, errors.New("user: Failed to reset user password") // this needs to be added as a named error
-		}
-	}
+func (api *AuthAPI) ResetUserPassword(ctx context.Context, email string) (*apiModels.APIResponse, error) {
+	request := api.client.BuildRequest(ctx).SetQueryParam("email", email)
 
-	return resp.Result().(*apiModels.APIResponse), nil
+	return client.Execute[apiModels.APIResponse](request, http.MethodGet, api.baseUrl+"/reset", resetPasswordErrors)
 }
@@ -0,0 +1,115 @@
+package bulk
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+/*
+Checkpoint - Tracks the sets that have been imported completely, so that an interrupted import can be resumed without
+sending their cards again. The checkpoint is persisted as JSON to a file after each set
+*/
+type Checkpoint struct {
+	// path - The path of the checkpoint file
+	path string
+
+	// completed - The codes of the sets that have been imported completely
+	completed map[string]bool
+
+	// mutex - Guards the completed sets and serializes writes of the checkpoint file
+	mutex sync.Mutex
+}
+
+/*
+checkpointFile - The structure of a checkpoint file
+*/
+type checkpointFile struct {
+	// CompletedSets - The codes of the sets that have been imported completely, in sorted order
+	CompletedSets []string `json:"completed_sets"`
+}
+
+/*
+OpenCheckpoint - Open the checkpoint file at the path passed in the parameter. A missing file is treated as an empty
+checkpoint, and is created once the first set has been imported
+*/
+func OpenCheckpoint(path string) (*Checkpoint, error) {
+	checkpoint := &Checkpoint{path: path, completed: make(map[string]bool)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return checkpoint, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var file checkpointFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	for _, code := range file.CompletedSets {
+		checkpoint.completed[code] = true
+	}
+
+	return checkpoint, nil
+}
+
+/*
+Completed - Returns true if the set under the code passed in the parameter has been imported completely
+*/
+func (checkpoint *Checkpoint) Completed(code string) bool {
+	checkpoint.mutex.Lock()
+	defer checkpoint.mutex.Unlock()
+
+	return checkpoint.completed[code]
+}
+
+/*
+Complete - Mark the set under the code passed in the parameter as imported and persist the checkpoint. The file is
+written to a temporary file first and then renamed, so that an interrupted write never corrupts the checkpoint
+*/
+func (checkpoint *Checkpoint) Complete(code string) error {
+	checkpoint.mutex.Lock()
+	defer checkpoint.mutex.Unlock()
+
+	checkpoint.completed[code] = true
+
+	file := checkpointFile{CompletedSets: make([]string, 0, len(checkpoint.completed))}
+	for completed := range checkpoint.completed {
+		file.CompletedSets = append(file.CompletedSets, completed)
+	}
+
+	sort.Strings(file.CompletedSets)
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(checkpoint.path), 0755); err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(checkpoint.path), ".checkpoint-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return err
+	}
+
+	if err := temp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(temp.Name(), checkpoint.path)
+}
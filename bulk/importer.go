@@ -0,0 +1,336 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	cardModel "github.com/stevezaluk/mtgjson-models/card"
+	sdkErrors "github.com/stevezaluk/mtgjson-models/errors"
+	setModel "github.com/stevezaluk/mtgjson-models/set"
+	"github.com/stevezaluk/mtgjson-sdk-client/api"
+	"github.com/stevezaluk/mtgjson-sdk-client/offline"
+	"io"
+	"slices"
+	"sync"
+)
+
+/*
+DefaultConcurrency - The number of cards created in parallel unless WithConcurrency is used
+*/
+const DefaultConcurrency = 8
+
+/*
+Counts - The number of items that were created, skipped because they already existed, or failed
+*/
+type Counts struct {
+	// Created - The number of items that were created
+	Created int
+
+	// Skipped - The number of items that already existed, or that belong to a set completed by a previous run
+	Skipped int
+
+	// Failed - The number of items that could not be imported
+	Failed int
+}
+
+/*
+Failure - A single item that could not be imported
+*/
+type Failure struct {
+	// Set - The code of the set the item belongs to
+	Set string
+
+	// Card - The MTGJSONv4 ID of the card that failed. This is empty if the set itself failed
+	Card string
+
+	// Err - The error returned for the item
+	Err error
+}
+
+/*
+Summary - The result of an import
+*/
+type Summary struct {
+	// Sets - The counts of the sets that were imported
+	Sets Counts
+
+	// Cards - The counts of the cards that were imported
+	Cards Counts
+
+	// Failures - Each item that could not be imported
+	Failures []Failure
+}
+
+/*
+String - Returns a single line describing the counts of the summary
+*/
+func (summary *Summary) String() string {
+	return fmt.Sprintf(
+		"sets: %d created, %d skipped, %d failed; cards: %d created, %d skipped, %d failed",
+		summary.Sets.Created, summary.Sets.Skipped, summary.Sets.Failed,
+		summary.Cards.Created, summary.Cards.Skipped, summary.Cards.Failed,
+	)
+}
+
+/*
+Importer - Pushes the sets and cards of MTGJSON AllPrintings or set files to a server through a CardService and a
+SetService. Importing is idempotent: cards and sets that already exist are skipped, and cards that are already in a
+set are not added to it again, so a failed import can simply be run again
+*/
+type Importer struct {
+	// cards - The service the cards are created with
+	cards api.CardService
+
+	// sets - The service the sets are created and filled with
+	sets api.SetService
+
+	// owner - The owner the sets and cards are assigned to
+	owner string
+
+	// concurrency - The maximum number of cards created in parallel
+	concurrency int
+
+	// checkpoint - Tracks the sets that have been imported completely. If nil, every set is imported
+	checkpoint *Checkpoint
+}
+
+/*
+Option - Configures an Importer when it is constructed with New
+*/
+type Option func(importer *Importer)
+
+/*
+WithOwner - Assign the imported sets and cards to the owner passed in the parameter instead of the system user
+*/
+func WithOwner(owner string) Option {
+	return func(importer *Importer) {
+		importer.owner = owner
+	}
+}
+
+/*
+WithConcurrency - Set the maximum number of cards created in parallel. Values below 1 are treated as 1
+*/
+func WithConcurrency(concurrency int) Option {
+	return func(importer *Importer) {
+		importer.concurrency = max(concurrency, 1)
+	}
+}
+
+/*
+WithCheckpoint - Skip the sets that the checkpoint marks as completed, and mark each set that was imported without
+failures. See OpenCheckpoint
+*/
+func WithCheckpoint(checkpoint *Checkpoint) Option {
+	return func(importer *Importer) {
+		importer.checkpoint = checkpoint
+	}
+}
+
+/*
+New - Create a new Importer that creates cards with the CardService and sets with the SetService passed in the
+parameter. Usually these are the Card and Set fields of an api.MtgjsonAPI
+*/
+func New(cards api.CardService, sets api.SetService, opts ...Option) *Importer {
+	importer := &Importer{
+		cards:       cards,
+		sets:        sets,
+		concurrency: DefaultConcurrency,
+	}
+
+	for _, opt := range opts {
+		opt(importer)
+	}
+
+	return importer
+}
+
+/*
+ImportFile - Import each set in the AllPrintings file or individual set file at the path passed in the parameter, see
+Import
+*/
+func (importer *Importer) ImportFile(ctx context.Context, path string) (*Summary, error) {
+	summary := &Summary{}
+
+	err := offline.ReadSetsFile(path, func(set *setModel.Set, cards []*cardModel.CardSet) error {
+		return importer.importSet(ctx, set, cards, summary)
+	})
+
+	return summary, err
+}
+
+/*
+Import - Import each set in the AllPrintings file or individual set file read from the reader passed in the parameter.
+The file is streamed one set at a time, see offline.ReadSets for the supported formats. For each set the cards are
+created first, then the set itself, and finally the cards that are not yet in the set are added to it. Failures of
+individual items are recorded in the returned Summary and do not stop the import; an error is only returned if the
+file cannot be read or the context is cancelled, in which case the Summary covers the items processed so far
+*/
+func (importer *Importer) Import(ctx context.Context, reader io.Reader) (*Summary, error) {
+	summary := &Summary{}
+
+	err := offline.ReadSets(reader, func(set *setModel.Set, cards []*cardModel.CardSet) error {
+		return importer.importSet(ctx, set, cards, summary)
+	})
+
+	return summary, err
+}
+
+/*
+importSet - Import a single set and its cards. The set is marked in the checkpoint only if nothing failed
+*/
+func (importer *Importer) importSet(ctx context.Context, set *setModel.Set, cards []*cardModel.CardSet, summary *Summary) error {
+	code := set.GetCode()
+
+	if importer.checkpoint != nil && importer.checkpoint.Completed(code) {
+		summary.Sets.Skipped++
+		summary.Cards.Skipped += len(cards)
+		return nil
+	}
+
+	failures := len(summary.Failures)
+
+	uuids := importer.createCards(ctx, code, cards, summary)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	_, err := importer.sets.NewSet(ctx, set, importer.owner)
+	created := err == nil
+
+	switch {
+	case created:
+		summary.Sets.Created++
+	case errors.Is(err, sdkErrors.ErrSetAlreadyExists):
+		summary.Sets.Skipped++
+	default:
+		return importer.setFailed(ctx, code, err, summary)
+	}
+
+	missing, err := importer.missingCards(ctx, code, uuids, created)
+	if err != nil {
+		return importer.setFailed(ctx, code, err, summary)
+	}
+
+	if len(missing) != 0 {
+		if _, err := importer.sets.AddCards(ctx, code, missing, importer.owner); err != nil {
+			return importer.setFailed(ctx, code, err, summary)
+		}
+	}
+
+	if importer.checkpoint == nil || len(summary.Failures) != failures {
+		return nil
+	}
+
+	return importer.checkpoint.Complete(code)
+}
+
+/*
+createCards - Create the cards passed in the parameter in parallel, bounded by the concurrency of the importer. Returns
+the MTGJSONv4 IDs of the cards that exist on the server afterward, in their original order
+*/
+func (importer *Importer) createCards(ctx context.Context, code string, cards []*cardModel.CardSet, summary *Summary) []string {
+	imported := make([]string, len(cards))
+	semaphore := make(chan struct{}, importer.concurrency)
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+
+	for index, card := range cards {
+		uuid := card.GetIdentifiers().GetMtgjsonV4Id()
+		if uuid == "" {
+			mutex.Lock()
+			summary.Cards.Failed++
+			summary.Failures = append(summary.Failures, Failure{Set: code, Err: sdkErrors.ErrCardMissingId})
+			mutex.Unlock()
+			continue
+		}
+
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			_, err := importer.cards.NewCard(ctx, card, importer.owner)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			switch {
+			case err == nil:
+				summary.Cards.Created++
+				imported[index] = uuid
+			case errors.Is(err, sdkErrors.ErrCardAlreadyExist):
+				summary.Cards.Skipped++
+				imported[index] = uuid
+			case ctx.Err() == nil: // cards interrupted by a cancellation are neither imported nor failed
+				summary.Cards.Failed++
+				summary.Failures = append(summary.Failures, Failure{Set: code, Card: uuid, Err: err})
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return slices.DeleteFunc(imported, func(uuid string) bool {
+		return uuid == ""
+	})
+}
+
+/*
+missingCards - Returns the MTGJSONv4 IDs passed in the parameter that are not yet in the set. A set that was just
+created is known to be empty, so its contents are only fetched if it already existed
+*/
+func (importer *Importer) missingCards(ctx context.Context, code string, uuids []string, created bool) ([]string, error) {
+	if created || len(uuids) == 0 {
+		return uuids, nil
+	}
+
+	contents, err := importer.sets.GetSetContents(ctx, code, importer.owner)
+	if errors.Is(err, sdkErrors.ErrNoCards) {
+		return uuids, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]bool, len(*contents))
+	for _, card := range *contents {
+		existing[card.GetIdentifiers().GetMtgjsonV4Id()] = true
+	}
+
+	missing := make([]string, 0, len(uuids))
+	for _, uuid := range uuids {
+		if !existing[uuid] {
+			missing = append(missing, uuid)
+			existing[uuid] = true // MTGJSON never lists a card twice, but a duplicate must not be added twice
+		}
+	}
+
+	return missing, nil
+}
+
+/*
+setFailed - Record the failure of a set in the summary. Returns the error of the context if it was cancelled, so that
+the import is stopped rather than continuing with the next set
+*/
+func (importer *Importer) setFailed(ctx context.Context, code string, err error, summary *Summary) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+
+	summary.Sets.Failed++
+	summary.Failures = append(summary.Failures, Failure{Set: code, Err: err})
+
+	return nil
+}
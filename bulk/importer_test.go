@@ -0,0 +1,166 @@
+package bulk_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	sdkErrors "github.com/stevezaluk/mtgjson-models/errors"
+	"github.com/stevezaluk/mtgjson-sdk-client/api"
+	"github.com/stevezaluk/mtgjson-sdk-client/bulk"
+	"github.com/stevezaluk/mtgjson-sdk-client/testserver"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+/*
+allPrintings - An AllPrintings file holding the sets LEA and M11
+*/
+const allPrintings = `{"data": {
+	"LEA": {"code": "LEA", "name": "Limited Edition Alpha", "cards": [
+		{"name": "Lightning Bolt", "identifiers": {"mtgjsonV4Id": "00000000-0000-0000-0000-000000000001"}},
+		{"name": "Counterspell", "identifiers": {"mtgjsonV4Id": "00000000-0000-0000-0000-000000000002"}}
+	]},
+	"M11": {"code": "M11", "name": "Magic 2011", "cards": [
+		{"name": "Lightning Bolt", "identifiers": {"mtgjsonV4Id": "00000000-0000-0000-0000-000000000003"}}
+	]}
+}}`
+
+/*
+newAPI - Start a testserver and return an MtgjsonAPI that targets it
+*/
+func newAPI(t *testing.T) *api.MtgjsonAPI {
+	server := testserver.New(testserver.WithoutAuth())
+	t.Cleanup(server.Close)
+
+	mtgjson, err := api.NewFromURL(server.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return mtgjson
+}
+
+/*
+setSize - Returns the number of cards in the set under the code passed in the parameter, failing the test if it
+cannot be fetched
+*/
+func setSize(t *testing.T, mtgjson *api.MtgjsonAPI, code string) int {
+	contents, err := mtgjson.Set.GetSetContents(context.Background(), code, "")
+	if err != nil {
+		t.Fatalf("GetSetContents(%s) error = %v", code, err)
+	}
+
+	return len(*contents)
+}
+
+func TestImportTwice(t *testing.T) {
+	ctx := context.Background()
+	mtgjson := newAPI(t)
+	importer := bulk.New(mtgjson.Card, mtgjson.Set)
+
+	tests := []struct {
+		name string
+		want bulk.Summary
+	}{
+		{name: "first import", want: bulk.Summary{Sets: bulk.Counts{Created: 2}, Cards: bulk.Counts{Created: 3}}},
+		{name: "second import", want: bulk.Summary{Sets: bulk.Counts{Skipped: 2}, Cards: bulk.Counts{Skipped: 3}}},
+	}
+
+	for _, test := range tests {
+		summary, err := importer.Import(ctx, strings.NewReader(allPrintings))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if summary.String() != test.want.String() || len(summary.Failures) != 0 {
+			t.Errorf("%s: summary = %s, failures = %v, want %s", test.name, summary, summary.Failures, &test.want)
+		}
+	}
+
+	if size := setSize(t, mtgjson, "LEA"); size != 2 {
+		t.Errorf("LEA holds %d cards after importing it twice, want 2", size)
+	}
+}
+
+func TestImportResumesFromCheckpoint(t *testing.T) {
+	ctx := context.Background()
+	mtgjson := newAPI(t)
+
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	if err := os.WriteFile(path, []byte(`{"completed_sets": ["LEA"]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	checkpoint, err := bulk.OpenCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	summary, err := bulk.New(mtgjson.Card, mtgjson.Set, bulk.WithCheckpoint(checkpoint)).Import(ctx, strings.NewReader(allPrintings))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := bulk.Summary{Sets: bulk.Counts{Created: 1, Skipped: 1}, Cards: bulk.Counts{Created: 1, Skipped: 2}}
+	if summary.String() != want.String() {
+		t.Errorf("summary = %s, want %s", summary, &want)
+	}
+
+	if _, err := mtgjson.Set.GetSet(ctx, "LEA", ""); !errors.Is(err, sdkErrors.ErrNoSet) {
+		t.Errorf("GetSet(LEA) error = %v, want the completed set to be skipped", err)
+	}
+
+	if size := setSize(t, mtgjson, "M11"); size != 1 {
+		t.Errorf("M11 holds %d cards, want 1", size)
+	}
+
+	reopened, err := bulk.OpenCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reopened.Completed("LEA") || !reopened.Completed("M11") {
+		t.Error("the checkpoint file does not mark both sets as completed")
+	}
+}
+
+func TestImportReportsRejectedCards(t *testing.T) {
+	ctx := context.Background()
+	mtgjson := newAPI(t)
+
+	checkpoint, err := bulk.OpenCheckpoint(filepath.Join(t.TempDir(), "checkpoint.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := strings.Replace(allPrintings, `"name": "Counterspell", `, "", 1) // the server rejects cards without a name
+
+	summary, err := bulk.New(mtgjson.Card, mtgjson.Set, bulk.WithCheckpoint(checkpoint)).Import(ctx, strings.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := bulk.Summary{Sets: bulk.Counts{Created: 2}, Cards: bulk.Counts{Created: 2, Failed: 1}}
+	if summary.String() != want.String() {
+		t.Errorf("summary = %s, want %s", summary, &want)
+	}
+
+	if len(summary.Failures) != 1 {
+		t.Fatalf("failures = %v, want the rejected card only", summary.Failures)
+	}
+
+	failure := summary.Failures[0]
+	if got := fmt.Sprintf("%s %s", failure.Set, failure.Card); got != "LEA 00000000-0000-0000-0000-000000000002" || !errors.Is(failure.Err, sdkErrors.ErrCardMissingId) {
+		t.Errorf("failure = %s %v, want the card of LEA rejected with ErrCardMissingId", got, failure.Err)
+	}
+
+	if size := setSize(t, mtgjson, "LEA"); size != 1 {
+		t.Errorf("LEA holds %d cards, want the card that was not rejected", size)
+	}
+
+	if checkpoint.Completed("LEA") || !checkpoint.Completed("M11") {
+		t.Error("the checkpoint must only mark the set without failures as completed")
+	}
+}
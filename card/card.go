@@ -1,13 +1,25 @@
 package card
 
 import (
+	"context"
+	"errors"
 	apiModels "github.com/stevezaluk/mtgjson-models/api"
 	cardModel "github.com/stevezaluk/mtgjson-models/card"
 	sdkErrors "github.com/stevezaluk/mtgjson-models/errors"
 	"github.com/stevezaluk/mtgjson-sdk-client/client"
+	"google.golang.org/protobuf/proto"
+	"iter"
+	"maps"
 	"net/http"
+	"slices"
+	"sync"
 )
 
+/*
+DefaultConcurrency - The number of cards GetCards fetches in parallel unless SetConcurrency is used
+*/
+const DefaultConcurrency = 8
+
 /*
 CardAPI A representation of the card namespace for the MTGJSON API
 */
@@ -17,18 +29,26 @@ type CardAPI struct {
 
 	// client - A pointer to the client.HTTPClient structure that is used for HTTP requests
 	client *client.HTTPClient
+
+	// concurrency - The maximum number of cards GetCards fetches in parallel
+	concurrency int
 }
 
 /*
-New Create a new instance of the CardAPI struct
+New Create a new instance of the CardAPI struct. The base URL must be an absolute http(s) URL and may
+contain a path prefix, client.ErrInvalidBaseURL is returned if it is malformed
 */
-func New(baseURL string, client *client.HTTPClient) *CardAPI {
-	// add error check for invalid url here
+func New(baseUrl string, httpClient *client.HTTPClient) (*CardAPI, error) {
+	endpoint, err := client.JoinURL(baseUrl, "card")
+	if err != nil {
+		return nil, err
+	}
 
 	return &CardAPI{
-		baseUrl: baseURL + "/card",
-		client:  client,
-	}
+		baseUrl:     endpoint,
+		client:      httpClient,
+		concurrency: DefaultConcurrency,
+	}, nil
 }
 
 /*
@@ -45,145 +65,353 @@ func (api *CardAPI) Client() *client.HTTPClient {
 	return api.client
 }
 
+/*
+SetConcurrency - Set the maximum number of cards that GetCards fetches in parallel. Values below 1 are treated as 1
+*/
+func (api *CardAPI) SetConcurrency(concurrency int) {
+	api.concurrency = max(concurrency, 1)
+}
+
+/*
+getCardErrors - Maps the status codes returned from GET /card to sentinel errors
+*/
+var getCardErrors = client.ErrorMap{
+	http.StatusNotFound:   {Default: sdkErrors.ErrNoCard},
+	http.StatusBadRequest: {Match: []error{sdkErrors.ErrInvalidUUID}},
+}
+
 /*
 GetCard Takes a single string representing an MTGJSONv4 UUID and return a card model
 for it
 */
-func (api *CardAPI) GetCard(uuid string, owner string) (*cardModel.CardSet, error) {
-	request := api.client.BuildRequest(&cardModel.CardSet{}).SetQueryParams(map[string]string{"cardId": uuid, "owner": owner})
+func (api *CardAPI) GetCard(ctx context.Context, uuid string, owner string) (*cardModel.CardSet, error) {
+	request := api.client.BuildRequest(ctx).SetQueryParams(map[string]string{"cardId": uuid, "owner": owner})
 
-	resp, err := request.Get(api.baseUrl)
-	if err != nil {
-		return nil, err
-	}
+	return client.Execute[cardModel.CardSet](request, http.MethodGet, api.baseUrl, getCardErrors)
+}
 
-	if resp.Error() != nil {
-		errorResponse := resp.Error().(*apiModels.APIResponse)
-		if resp.StatusCode() == http.StatusUnauthorized {
-			return nil, sdkErrors.ErrTokenInvalid
+/*
+GetCards Fetch the cards under each of the MTGJSONv4 UUIDs passed in the parameter, making up to the
+concurrency of the CardAPI requests in parallel. Duplicate UUIDs are fetched once, and the cards that were
+found are returned in the order that their UUIDs first appear. UUIDs that do not resolve to a card are not
+treated as a failure of the batch, instead the error for each of them (ErrNoCard or ErrInvalidUUID) is
+returned in a map keyed by UUID. An empty UUID is recorded as ErrInvalidUUID without making a request, as
+the API would return the index of cards for it instead. Any other error, such as a network failure or
+cancellation of the context, stops the remaining requests and is returned on its own
+*/
+func (api *CardAPI) GetCards(ctx context.Context, uuids []string, owner string) ([]*cardModel.CardSet, map[string]error, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	uuids = unique(uuids)
+	cards := make([]*cardModel.CardSet, len(uuids))
+	missing := make(map[string]error)
+	semaphore := make(chan struct{}, max(api.concurrency, 1))
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	var failure error
+
+	for index, uuid := range uuids {
+		if uuid == "" {
+			mutex.Lock()
+			missing[uuid] = sdkErrors.ErrInvalidUUID
+			mutex.Unlock()
+
+			continue
 		}
 
-		if resp.StatusCode() == http.StatusForbidden {
-			return nil, sdkErrors.ErrInvalidPermissions
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
 		}
 
-		if resp.StatusCode() == http.StatusNotFound {
-			return nil, sdkErrors.ErrNoCard
+		if ctx.Err() != nil {
+			break
 		}
 
-		if resp.StatusCode() == http.StatusBadRequest {
-			if errorResponse.Err == sdkErrors.ErrInvalidUUID.Error() {
-				return nil, sdkErrors.ErrInvalidUUID
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			card, err := api.GetCard(ctx, uuid, owner)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			switch {
+			case err == nil:
+				cards[index] = card
+			case errors.Is(err, sdkErrors.ErrNoCard) || errors.Is(err, sdkErrors.ErrInvalidUUID):
+				missing[uuid] = err
+			case failure == nil:
+				failure = err
+				cancel()
 			}
+		}()
+	}
+
+	wg.Wait()
+
+	if failure == nil {
+		failure = ctx.Err()
+	}
+
+	if failure != nil {
+		return nil, nil, failure
+	}
+
+	return slices.DeleteFunc(cards, func(card *cardModel.CardSet) bool {
+		return card == nil
+	}), missing, nil
+}
+
+/*
+unique - Returns the strings passed in the parameter with duplicates removed, keeping the first occurrence of each
+*/
+func unique(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
 		}
 	}
 
-	return resp.Result().(*cardModel.CardSet), nil
+	return result
 }
 
 /*
-IndexCards Returns all cards in the database unmarshalled as card models. The limit parameter
-will be passed directly to the database query to limit the number of models returned
+indexCardsErrors - Maps the status codes returned from GET /card (without a card ID) to sentinel errors
 */
-func (api *CardAPI) IndexCards() (*[]*cardModel.CardSet, error) {
-	request := api.client.BuildRequest(&[]*cardModel.CardSet{})
+var indexCardsErrors = client.ErrorMap{
+	http.StatusNotFound: {Default: sdkErrors.ErrNoCards},
+}
 
-	resp, err := request.Get(api.baseUrl)
-	if err != nil {
-		return nil, err
-	}
+/*
+IndexCards Returns a page of cards from the database unmarshalled as card models. The limit and offset
+parameters are passed directly to the database query to page through the cards, a value of 0 or less
+omits them. Returns ErrNoCards if the page is empty
+*/
+func (api *CardAPI) IndexCards(ctx context.Context, limit int, offset int) (*[]*cardModel.CardSet, error) {
+	request := api.client.BuildRequest(ctx).SetQueryParams(client.PageParams(limit, offset))
 
-	if resp.Error() != nil {
-		if resp.StatusCode() == http.StatusUnauthorized {
-			return nil, sdkErrors.ErrTokenInvalid
-		}
+	return client.Execute[[]*cardModel.CardSet](request, http.MethodGet, api.baseUrl, indexCardsErrors)
+}
 
-		if resp.StatusCode() == http.StatusForbidden {
-			return nil, sdkErrors.ErrInvalidPermissions
-		}
+/*
+IterCards Returns an iterator over every card in the database. Pages of pageSize cards are fetched lazily
+as the iterator is advanced (client.DefaultPageSize if pageSize is 0 or less). An error fetching a page,
+including cancellation of the context, is yielded once and ends the iteration
+*/
+func (api *CardAPI) IterCards(ctx context.Context, pageSize int) iter.Seq2[*cardModel.CardSet, error] {
+	return client.Paginate(ctx, pageSize, sdkErrors.ErrNoCards, api.IndexCards)
+}
 
-		if resp.StatusCode() == http.StatusNotFound {
-			return nil, sdkErrors.ErrNoCards
-		}
-	}
+/*
+StreamCards Returns an iterator over a page of cards from the database, selected by the limit and offset
+parameters in the same way as IndexCards. Unlike IndexCards, the response is decoded one card at a time as
+the iterator is advanced, so that large pages never have to be held in memory as a whole. An error, including
+ErrNoCards for an empty page, is yielded once and ends the iteration
+*/
+func (api *CardAPI) StreamCards(ctx context.Context, limit int, offset int) iter.Seq2[*cardModel.CardSet, error] {
+	return client.Seq(func(fn func(card *cardModel.CardSet) error) error {
+		request := api.client.BuildRequest(ctx).SetQueryParams(client.PageParams(limit, offset))
+
+		return client.Stream(request, http.MethodGet, api.baseUrl, indexCardsErrors, fn)
+	})
+}
+
+/*
+Search Returns an iterator over the cards in the database that match the query. The query is sent to the
+API as query parameters alongside the limit and offset of each page, and is re-applied to every page that is
+returned, so that filters the server does not support are still applied client-side. Pages of pageSize cards
+are fetched lazily (client.DefaultPageSize if pageSize is 0 or less). An error fetching a page, including
+cancellation of the context, is yielded once and ends the iteration
+*/
+func (api *CardAPI) Search(ctx context.Context, query *CardQuery, pageSize int) iter.Seq2[*cardModel.CardSet, error] {
+	return query.Filter(client.Paginate(ctx, pageSize, sdkErrors.ErrNoCards, func(ctx context.Context, limit int, offset int) (*[]*cardModel.CardSet, error) {
+		params := query.Params()
+		maps.Copy(params, client.PageParams(limit, offset))
+
+		request := api.client.BuildRequest(ctx).SetQueryParams(params)
+
+		return client.Execute[[]*cardModel.CardSet](request, http.MethodGet, api.baseUrl, indexCardsErrors)
+	}))
+}
 
-	return resp.Result().(*[]*cardModel.CardSet), nil
+/*
+newCardErrors - Maps the status codes returned from POST /card to sentinel errors
+*/
+var newCardErrors = client.ErrorMap{
+	http.StatusConflict:   {Default: sdkErrors.ErrCardAlreadyExist},
+	http.StatusBadRequest: {Default: sdkErrors.ErrCardMissingId},
 }
 
 /*
 NewCard Insert a new card in the form of a model into the MongoDB database. The card model must have a
 valid name and MTGJSONv4 ID, additionally, the card cannot already exist under the same ID
 */
-func (api *CardAPI) NewCard(card *cardModel.CardSet, owner string) (*apiModels.APIResponse, error) {
-	request := api.client.BuildRequest(&apiModels.APIResponse{}).
+func (api *CardAPI) NewCard(ctx context.Context, card *cardModel.CardSet, owner string) (*apiModels.APIResponse, error) {
+	request := api.client.BuildRequest(ctx).
 		SetBody(card).
 		SetQueryParam("owner", owner)
 
-	resp, err := request.Post(api.baseUrl)
+	return client.Execute[apiModels.APIResponse](request, http.MethodPost, api.baseUrl, newCardErrors)
+}
+
+/*
+updateCardErrors - Maps the status codes returned from PUT and PATCH /card to sentinel errors
+*/
+var updateCardErrors = client.ErrorMap{
+	http.StatusNotFound: {Default: sdkErrors.ErrNoCard},
+	http.StatusBadRequest: {Match: []error{
+		sdkErrors.ErrMetaApiMustBeNull,
+		sdkErrors.ErrInvalidUUID,
+		sdkErrors.ErrCardMissingId,
+	}},
+}
+
+/*
+UpdateCard Replace the card stored under the MTGJSONv4 ID of the card model passed in the parameter. The
+API metadata of the model is not sent, as the API maintains it, but its modified date is used to detect
+conflicting changes: if the stored card was modified after the model was fetched then client.ErrConflict
+is returned and nothing is changed. A model without metadata replaces the card unconditionally. Returns
+ErrCardMissingId if the model is nil. PUT /card, the If-Match header and the 412 mapped to client.ErrConflict
+require a server that supports them, which the upstream MTGJSON API does not provide yet
+*/
+func (api *CardAPI) UpdateCard(ctx context.Context, card *cardModel.CardSet, owner string) (*apiModels.APIResponse, error) {
+	body, err := withoutMeta(card)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.Error() != nil {
-		errorResponse := resp.Error().(*apiModels.APIResponse)
+	request := client.SetIfMatch(api.client.BuildRequest(ctx), card.GetMtgjsonApiMeta().GetModifiedDate()).
+		SetQueryParams(map[string]string{"cardId": card.GetIdentifiers().GetMtgjsonV4Id(), "owner": owner}).
+		SetBody(body)
 
-		if resp.StatusCode() == http.StatusUnauthorized {
-			return errorResponse, sdkErrors.ErrTokenInvalid
-		}
+	return client.Execute[apiModels.APIResponse](request, http.MethodPut, api.baseUrl, updateCardErrors)
+}
 
-		if resp.StatusCode() == http.StatusForbidden {
-			return errorResponse, sdkErrors.ErrInvalidPermissions
-		}
+/*
+PatchCard Update only the fields that differ between the original card model, as it was fetched, and
+the updated one, by sending a JSON merge patch. Fields changed concurrently by someone else are left
+intact, however the modified date of original is still used to detect conflicts in the same way as
+UpdateCard. Returns ErrCardMissingId if either model is nil. Like UpdateCard, this requires PATCH /card support
+on the server
+*/
+func (api *CardAPI) PatchCard(ctx context.Context, original *cardModel.CardSet, updated *cardModel.CardSet, owner string) (*apiModels.APIResponse, error) {
+	originalBody, err := withoutMeta(original)
+	if err != nil {
+		return nil, err
+	}
 
-		if resp.StatusCode() == http.StatusConflict {
-			return errorResponse, sdkErrors.ErrCardAlreadyExist
-		}
+	updatedBody, err := withoutMeta(updated)
+	if err != nil {
+		return nil, err
+	}
 
-		if resp.StatusCode() == http.StatusBadRequest {
-			return errorResponse, sdkErrors.ErrCardMissingId
-		}
+	patch, err := client.MergePatch(originalBody, updatedBody)
+	if err != nil {
+		return nil, err
 	}
 
-	return resp.Result().(*apiModels.APIResponse), nil
+	request := client.SetIfMatch(api.client.BuildRequest(ctx), original.GetMtgjsonApiMeta().GetModifiedDate()).
+		SetQueryParams(map[string]string{"cardId": original.GetIdentifiers().GetMtgjsonV4Id(), "owner": owner}).
+		SetHeader("Content-Type", client.MergePatchContentType).
+		SetBody(patch)
+
+	return client.Execute[apiModels.APIResponse](request, http.MethodPatch, api.baseUrl, updateCardErrors)
 }
 
 /*
-DeleteCard Remove a card from the MongoDB database. The UUID passed in the parameter must be a valid MTGJSONv4 ID.
-ErrNoCard will be returned if no card exists under the passed UUID, and ErrCardDeleteFailed will be returned
-if the deleted count does not equal 1
+UpsertCard Create the card passed in the parameter if no card exists under its MTGJSONv4 ID, or update
+the existing card if any of the fields set in the card model differ from it. Fields that the model leaves
+unset, or sets to their zero value, keep their stored value, see client.Overlay; use UpdateCard or PatchCard
+to clear them. The API metadata of both models is ignored when comparing them, and the update is sent with
+PatchCard, so client.ErrConflict is returned if the card is modified concurrently. Returns the action that
+was taken, or ErrCardMissingId without sending a request if the model is nil or has no MTGJSONv4 ID
 */
-func (api *CardAPI) DeleteCard(uuid string, owner string) (*apiModels.APIResponse, error) {
-	request := api.client.BuildRequest(&apiModels.APIResponse{}).
-		SetQueryParams(map[string]string{"cardId": uuid, "owner": owner})
-
-	resp, err := request.Delete(api.baseUrl)
+func (api *CardAPI) UpsertCard(ctx context.Context, card *cardModel.CardSet, owner string) (client.UpsertAction, error) {
+	desired, err := withoutMeta(card)
 	if err != nil {
-		return nil, err
+		return client.UpsertUnchanged, err
 	}
 
-	if resp.Error() != nil {
-		errorResponse := resp.Error().(*apiModels.APIResponse)
+	uuid := card.GetIdentifiers().GetMtgjsonV4Id()
+	if uuid == "" { // an empty ID would fetch the card index instead of a single card
+		return client.UpsertUnchanged, sdkErrors.ErrCardMissingId
+	}
 
-		if resp.StatusCode() == http.StatusUnauthorized {
-			return errorResponse, sdkErrors.ErrTokenInvalid
+	existing, err := api.GetCard(ctx, uuid, owner)
+	if errors.Is(err, sdkErrors.ErrNoCard) {
+		if _, err := api.NewCard(ctx, desired, owner); err != nil {
+			return client.UpsertUnchanged, err
 		}
 
-		if resp.StatusCode() == http.StatusForbidden {
-			return errorResponse, sdkErrors.ErrInvalidPermissions
-		}
+		return client.UpsertCreated, nil
+	}
 
-		if resp.StatusCode() == http.StatusNotFound {
-			return errorResponse, sdkErrors.ErrNoCard
-		}
+	if err != nil {
+		return client.UpsertUnchanged, err
+	}
 
-		if resp.StatusCode() == http.StatusBadRequest {
-			return errorResponse, sdkErrors.ErrCardMissingId
-		}
+	current, err := withoutMeta(existing)
+	if err != nil {
+		return client.UpsertUnchanged, err
+	}
 
-		if resp.StatusCode() == http.StatusInternalServerError {
-			return errorResponse, sdkErrors.ErrCardDeleteFailed
-		}
+	merged, err := client.Overlay(current, desired)
+	if err != nil {
+		return client.UpsertUnchanged, err
+	}
+
+	if proto.Equal(current, merged) {
+		return client.UpsertUnchanged, nil
+	}
+
+	if _, err := api.PatchCard(ctx, existing, merged, owner); err != nil {
+		return client.UpsertUnchanged, err
 	}
 
-	return resp.Result().(*apiModels.APIResponse), nil
+	return client.UpsertUpdated, nil
+}
+
+/*
+withoutMeta - Returns a copy of the card model with its API metadata removed, as the API rejects models that carry it.
+Returns ErrCardMissingId if the model is nil
+*/
+func withoutMeta(card *cardModel.CardSet) (*cardModel.CardSet, error) {
+	if card == nil {
+		return nil, sdkErrors.ErrCardMissingId
+	}
+
+	card = proto.Clone(card).(*cardModel.CardSet)
+	card.MtgjsonApiMeta = nil
+
+	return card, nil
+}
+
+/*
+deleteCardErrors - Maps the status codes returned from DELETE /card to sentinel errors
+*/
+var deleteCardErrors = client.ErrorMap{
+	http.StatusNotFound:            {Default: sdkErrors.ErrNoCard},
+	http.StatusBadRequest:          {Default: sdkErrors.ErrCardMissingId},
+	http.StatusInternalServerError: {Default: sdkErrors.ErrCardDeleteFailed},
+}
+
+/*
+DeleteCard Remove a card from the MongoDB database. The UUID passed in the parameter must be a valid MTGJSONv4 ID.
+ErrNoCard will be returned if no card exists under the passed UUID, and ErrCardDeleteFailed will be returned
+if the deleted count does not equal 1
+*/
+func (api *CardAPI) DeleteCard(ctx context.Context, uuid string, owner string) (*apiModels.APIResponse, error) {
+	request := api.client.BuildRequest(ctx).
+		SetQueryParams(map[string]string{"cardId": uuid, "owner": owner})
+
+	return client.Execute[apiModels.APIResponse](request, http.MethodDelete, api.baseUrl, deleteCardErrors)
 }
@@ -0,0 +1,322 @@
+package card
+
+import (
+	cardModel "github.com/stevezaluk/mtgjson-models/card"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+/*
+legalities - Returns the legality of a card in each format that CardQuery.LegalIn accepts, keyed by the name of the
+format as it appears in MTGJSON
+*/
+var legalities = map[string]func(card *cardModel.CardSet) string{
+	"standard":  func(card *cardModel.CardSet) string { return card.GetLegalities().GetStandard() },
+	"pioneer":   func(card *cardModel.CardSet) string { return card.GetLegalities().GetPioneer() },
+	"modern":    func(card *cardModel.CardSet) string { return card.GetLegalities().GetModern() },
+	"legacy":    func(card *cardModel.CardSet) string { return card.GetLegalities().GetLegacy() },
+	"vintage":   func(card *cardModel.CardSet) string { return card.GetLegalities().GetVintage() },
+	"pauper":    func(card *cardModel.CardSet) string { return card.GetLegalities().GetPauper() },
+	"commander": func(card *cardModel.CardSet) string { return card.GetLegalities().GetCommander() },
+	"brawl":     func(card *cardModel.CardSet) string { return card.GetLegalities().GetBrawl() },
+}
+
+/*
+Legality - Returns the legality of the card in the format passed in the parameter, as it appears in MTGJSON ("Legal",
+"Restricted", "Banned" or "Not Legal"). The format name is case-insensitive. Returns an empty string if the format is
+unknown or the card has no legality for it
+*/
+func Legality(card *cardModel.CardSet, format string) string {
+	legality, ok := legalities[strings.ToLower(format)]
+	if !ok {
+		return ""
+	}
+
+	return legality(card)
+}
+
+/*
+CardQuery - A typed search over cards, built by chaining its methods onto NewQuery. Each method narrows the query, so
+a card must match every filter that is set. Params serializes the query into the query parameters sent to the API,
+while Matches applies it client-side for filters that the server does not support. A nil CardQuery matches every card
+*/
+type CardQuery struct {
+	// name - The exact name that a card must have, compared case-insensitively
+	name string
+
+	// nameContains - A substring that the name of a card must contain, compared case-insensitively
+	nameContains string
+
+	// colors - The exact colors that a card must have. A nil slice leaves the filter unset, while an empty one
+	// selects colorless cards
+	colors []string
+
+	// colorIdentity - The colors that the color identity of a card must be within. A nil slice leaves the filter
+	// unset, while an empty one selects cards with a colorless identity
+	colorIdentity []string
+
+	// minManaValue - The lowest mana value that a card may have, nil if unset
+	minManaValue *float64
+
+	// maxManaValue - The highest mana value that a card may have, nil if unset
+	maxManaValue *float64
+
+	// types - The card types that a card must all have
+	types []string
+
+	// subtypes - The subtypes that a card must all have
+	subtypes []string
+
+	// supertypes - The supertypes that a card must all have
+	supertypes []string
+
+	// rarity - The rarity that a card must have
+	rarity string
+
+	// setCode - The code of the set that a card must be printed in
+	setCode string
+
+	// formats - The formats that a card must be legal in
+	formats []string
+}
+
+/*
+NewQuery - Returns an empty CardQuery, which matches every card until it is narrowed
+*/
+func NewQuery() *CardQuery {
+	return &CardQuery{}
+}
+
+/*
+Name - Select cards whose name is exactly the name passed in the parameter, ignoring case
+*/
+func (query *CardQuery) Name(name string) *CardQuery {
+	query.name = name
+	return query
+}
+
+/*
+NameContains - Select cards whose name contains the substring passed in the parameter, ignoring case
+*/
+func (query *CardQuery) NameContains(substring string) *CardQuery {
+	query.nameContains = substring
+	return query
+}
+
+/*
+Colors - Select cards whose colors are exactly the colors passed in the parameter, in any order. Colors are given as
+their MTGJSON abbreviations (W, U, B, R, G). Calling this without any colors selects colorless cards
+*/
+func (query *CardQuery) Colors(colors ...string) *CardQuery {
+	query.colors = append([]string{}, colors...)
+	return query
+}
+
+/*
+ColorIdentity - Select cards whose color identity is within the colors passed in the parameter, in the same way that
+the color identity of a commander restricts the cards of a deck. Calling this without any colors selects cards with a
+colorless identity
+*/
+func (query *CardQuery) ColorIdentity(colors ...string) *CardQuery {
+	query.colorIdentity = append([]string{}, colors...)
+	return query
+}
+
+/*
+MinManaValue - Select cards with a mana value greater than or equal to the value passed in the parameter
+*/
+func (query *CardQuery) MinManaValue(manaValue float64) *CardQuery {
+	query.minManaValue = &manaValue
+	return query
+}
+
+/*
+MaxManaValue - Select cards with a mana value less than or equal to the value passed in the parameter
+*/
+func (query *CardQuery) MaxManaValue(manaValue float64) *CardQuery {
+	query.maxManaValue = &manaValue
+	return query
+}
+
+/*
+Types - Select cards that have every card type passed in the parameter, such as Creature or Artifact
+*/
+func (query *CardQuery) Types(types ...string) *CardQuery {
+	query.types = append(query.types, types...)
+	return query
+}
+
+/*
+Subtypes - Select cards that have every subtype passed in the parameter, such as Elf or Equipment
+*/
+func (query *CardQuery) Subtypes(subtypes ...string) *CardQuery {
+	query.subtypes = append(query.subtypes, subtypes...)
+	return query
+}
+
+/*
+Supertypes - Select cards that have every supertype passed in the parameter, such as Legendary or Basic
+*/
+func (query *CardQuery) Supertypes(supertypes ...string) *CardQuery {
+	query.supertypes = append(query.supertypes, supertypes...)
+	return query
+}
+
+/*
+Rarity - Select cards of the rarity passed in the parameter, such as common or mythic
+*/
+func (query *CardQuery) Rarity(rarity string) *CardQuery {
+	query.rarity = rarity
+	return query
+}
+
+/*
+SetCode - Select cards printed in the set under the set code passed in the parameter
+*/
+func (query *CardQuery) SetCode(code string) *CardQuery {
+	query.setCode = code
+	return query
+}
+
+/*
+LegalIn - Select cards that are legal (or restricted) in each format passed in the parameter. The supported formats
+are standard, pioneer, modern, legacy, vintage, pauper, commander and brawl; an unknown format matches no cards
+*/
+func (query *CardQuery) LegalIn(formats ...string) *CardQuery {
+	query.formats = append(query.formats, formats...)
+	return query
+}
+
+/*
+Params - Returns the query parameters that the query is sent to the API as. Lists are joined with commas, and filters
+that are not set are omitted
+*/
+func (query *CardQuery) Params() map[string]string {
+	params := make(map[string]string)
+	if query == nil {
+		return params
+	}
+
+	setParam := func(key string, value string) {
+		if value != "" {
+			params[key] = value
+		}
+	}
+
+	setParam("name", query.name)
+	setParam("nameContains", query.nameContains)
+	setParam("types", strings.Join(query.types, ","))
+	setParam("subtypes", strings.Join(query.subtypes, ","))
+	setParam("supertypes", strings.Join(query.supertypes, ","))
+	setParam("rarity", query.rarity)
+	setParam("setCode", query.setCode)
+	setParam("legalIn", strings.Join(query.formats, ","))
+
+	if query.colors != nil {
+		params["colors"] = strings.Join(query.colors, ",") // an empty value selects colorless cards
+	}
+
+	if query.colorIdentity != nil {
+		params["colorIdentity"] = strings.Join(query.colorIdentity, ",")
+	}
+
+	if query.minManaValue != nil {
+		params["minManaValue"] = strconv.FormatFloat(*query.minManaValue, 'f', -1, 64)
+	}
+
+	if query.maxManaValue != nil {
+		params["maxManaValue"] = strconv.FormatFloat(*query.maxManaValue, 'f', -1, 64)
+	}
+
+	return params
+}
+
+/*
+Matches - Returns true if the card passed in the parameter satisfies every filter of the query
+*/
+func (query *CardQuery) Matches(card *cardModel.CardSet) bool {
+	if query == nil {
+		return true
+	}
+
+	if query.name != "" && !strings.EqualFold(card.GetName(), query.name) {
+		return false
+	}
+
+	if query.nameContains != "" && !strings.Contains(strings.ToLower(card.GetName()), strings.ToLower(query.nameContains)) {
+		return false
+	}
+
+	if query.colors != nil && !(containsAll(card.GetColors(), query.colors) && containsAll(query.colors, card.GetColors())) {
+		return false
+	}
+
+	if query.colorIdentity != nil && !containsAll(query.colorIdentity, card.GetColorIdentity()) {
+		return false
+	}
+
+	manaValue := float64(card.GetManaValue())
+	if query.minManaValue != nil && manaValue < *query.minManaValue {
+		return false
+	}
+
+	if query.maxManaValue != nil && manaValue > *query.maxManaValue {
+		return false
+	}
+
+	if !containsAll(card.GetTypes(), query.types) ||
+		!containsAll(card.GetSubtypes(), query.subtypes) ||
+		!containsAll(card.GetSupertypes(), query.supertypes) {
+		return false
+	}
+
+	if query.rarity != "" && !strings.EqualFold(card.GetRarity(), query.rarity) {
+		return false
+	}
+
+	if query.setCode != "" && !strings.EqualFold(card.GetSetCode(), query.setCode) {
+		return false
+	}
+
+	for _, format := range query.formats {
+		legality := Legality(card, format)
+		if legality != "Legal" && legality != "Restricted" {
+			return false
+		}
+	}
+
+	return true
+}
+
+/*
+Filter - Returns an iterator over the cards of the iterator passed in the parameter that match the query. Errors are
+passed through unchanged
+*/
+func (query *CardQuery) Filter(cards iter.Seq2[*cardModel.CardSet, error]) iter.Seq2[*cardModel.CardSet, error] {
+	return func(yield func(*cardModel.CardSet, error) bool) {
+		for card, err := range cards {
+			if err == nil && !query.Matches(card) {
+				continue
+			}
+
+			if !yield(card, err) {
+				return
+			}
+		}
+	}
+}
+
+/*
+containsAll - Returns true if every value in want is in have, ignoring case
+*/
+func containsAll(have []string, want []string) bool {
+	for _, value := range want {
+		if !slices.ContainsFunc(have, func(candidate string) bool { return strings.EqualFold(candidate, value) }) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,229 @@
+package card_test
+
+import (
+	"context"
+	"fmt"
+	cardModel "github.com/stevezaluk/mtgjson-models/card"
+	"github.com/stevezaluk/mtgjson-sdk-client/card"
+	"github.com/stevezaluk/mtgjson-sdk-client/client"
+	"github.com/stevezaluk/mtgjson-sdk-client/testserver"
+	"maps"
+	"net/http"
+	"slices"
+	"sync/atomic"
+	"testing"
+)
+
+/*
+Fixture cards - The cards that the queries of the tests are matched against
+*/
+var (
+	bolt = &cardModel.CardSet{
+		Name:          "Lightning Bolt",
+		Colors:        []string{"R"},
+		ColorIdentity: []string{"R"},
+		ManaValue:     1,
+		Types:         []string{"Instant"},
+		Rarity:        "common",
+		SetCode:       "M11",
+		Legalities:    &cardModel.Legalities{Modern: "Legal", Vintage: "Restricted"},
+	}
+	helix = &cardModel.CardSet{
+		Name:          "Lightning Helix",
+		Colors:        []string{"R", "W"},
+		ColorIdentity: []string{"R", "W"},
+		ManaValue:     2,
+		Types:         []string{"Instant"},
+		Rarity:        "uncommon",
+		SetCode:       "RAV",
+		Legalities:    &cardModel.Legalities{Modern: "Legal", Vintage: "Legal"},
+	}
+	elves = &cardModel.CardSet{
+		Name:          "Llanowar Elves",
+		Colors:        []string{"G"},
+		ColorIdentity: []string{"G"},
+		ManaValue:     1,
+		Types:         []string{"Creature"},
+		Subtypes:      []string{"Elf", "Druid"},
+		Rarity:        "common",
+		SetCode:       "M19",
+		Legalities:    &cardModel.Legalities{Modern: "Banned"},
+	}
+	golem = &cardModel.CardSet{
+		Name:      "Dross Golem",
+		ManaValue: 5,
+		Types:     []string{"Artifact", "Creature"},
+		Subtypes:  []string{"Golem"},
+		Rarity:    "common",
+		SetCode:   "FD1",
+	}
+	krenko = &cardModel.CardSet{
+		Name:          "Krenko, Mob Boss",
+		Colors:        []string{"R"},
+		ColorIdentity: []string{"R"},
+		ManaValue:     4,
+		Types:         []string{"Creature"},
+		Subtypes:      []string{"Goblin", "Warrior"},
+		Supertypes:    []string{"Legendary"},
+		Rarity:        "rare",
+		SetCode:       "M13",
+		Legalities:    &cardModel.Legalities{Commander: "Legal"},
+	}
+)
+
+/*
+matching - Returns the names of the fixture cards that match the query passed in the parameter
+*/
+func matching(query *card.CardQuery) []string {
+	var names []string
+	for _, fixture := range []*cardModel.CardSet{bolt, helix, elves, golem, krenko} {
+		if query.Matches(fixture) {
+			names = append(names, fixture.GetName())
+		}
+	}
+
+	return names
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		name  string
+		query *card.CardQuery
+		want  []string
+	}{
+		{name: "nil query", query: nil, want: []string{"Lightning Bolt", "Lightning Helix", "Llanowar Elves", "Dross Golem", "Krenko, Mob Boss"}},
+		{name: "exact name ignores case", query: card.NewQuery().Name("lightning bolt"), want: []string{"Lightning Bolt"}},
+		{name: "name substring", query: card.NewQuery().NameContains("LIGHTNING"), want: []string{"Lightning Bolt", "Lightning Helix"}},
+		{name: "exact colors", query: card.NewQuery().Colors("R"), want: []string{"Lightning Bolt", "Krenko, Mob Boss"}},
+		{name: "exact colors in any order", query: card.NewQuery().Colors("W", "R"), want: []string{"Lightning Helix"}},
+		{name: "colorless", query: card.NewQuery().Colors(), want: []string{"Dross Golem"}},
+		{
+			name:  "color identity is a subset",
+			query: card.NewQuery().ColorIdentity("R", "W"),
+			want:  []string{"Lightning Bolt", "Lightning Helix", "Dross Golem", "Krenko, Mob Boss"},
+		},
+		{name: "colorless identity", query: card.NewQuery().ColorIdentity(), want: []string{"Dross Golem"}},
+		{name: "minimum mana value is inclusive", query: card.NewQuery().MinManaValue(4), want: []string{"Dross Golem", "Krenko, Mob Boss"}},
+		{name: "maximum mana value is inclusive", query: card.NewQuery().MaxManaValue(1), want: []string{"Lightning Bolt", "Llanowar Elves"}},
+		{name: "mana value range", query: card.NewQuery().MinManaValue(2).MaxManaValue(4), want: []string{"Lightning Helix", "Krenko, Mob Boss"}},
+		{name: "every type", query: card.NewQuery().Types("creature", "Artifact"), want: []string{"Dross Golem"}},
+		{name: "subtype", query: card.NewQuery().Subtypes("Elf"), want: []string{"Llanowar Elves"}},
+		{name: "supertype", query: card.NewQuery().Supertypes("Legendary"), want: []string{"Krenko, Mob Boss"}},
+		{name: "rarity", query: card.NewQuery().Rarity("COMMON").Types("Creature"), want: []string{"Llanowar Elves", "Dross Golem"}},
+		{name: "set code", query: card.NewQuery().SetCode("m11"), want: []string{"Lightning Bolt"}},
+		{name: "legal in", query: card.NewQuery().LegalIn("modern"), want: []string{"Lightning Bolt", "Lightning Helix"}},
+		{name: "restricted counts as legal", query: card.NewQuery().LegalIn("Vintage"), want: []string{"Lightning Bolt", "Lightning Helix"}},
+		{name: "missing legality", query: card.NewQuery().LegalIn("commander"), want: []string{"Krenko, Mob Boss"}},
+		{name: "unknown format", query: card.NewQuery().LegalIn("oathbreaker"), want: nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := matching(test.query); !slices.Equal(got, test.want) {
+				t.Errorf("matching cards = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParams(t *testing.T) {
+	tests := []struct {
+		name  string
+		query *card.CardQuery
+		want  map[string]string
+	}{
+		{name: "nil query", query: nil, want: map[string]string{}},
+		{name: "empty query", query: card.NewQuery(), want: map[string]string{}},
+		{name: "name", query: card.NewQuery().Name("Lightning Bolt"), want: map[string]string{"name": "Lightning Bolt"}},
+		{name: "name substring", query: card.NewQuery().NameContains("bolt"), want: map[string]string{"nameContains": "bolt"}},
+		{name: "colors", query: card.NewQuery().Colors("R", "W"), want: map[string]string{"colors": "R,W"}},
+		{name: "colorless", query: card.NewQuery().Colors(), want: map[string]string{"colors": ""}},
+		{name: "color identity", query: card.NewQuery().ColorIdentity("G"), want: map[string]string{"colorIdentity": "G"}},
+		{
+			name:  "mana values",
+			query: card.NewQuery().MinManaValue(0).MaxManaValue(2.5),
+			want:  map[string]string{"minManaValue": "0", "maxManaValue": "2.5"},
+		},
+		{
+			name:  "types",
+			query: card.NewQuery().Types("Artifact").Types("Creature").Subtypes("Golem").Supertypes("Legendary", "Snow"),
+			want:  map[string]string{"types": "Artifact,Creature", "subtypes": "Golem", "supertypes": "Legendary,Snow"},
+		},
+		{name: "rarity", query: card.NewQuery().Rarity("mythic"), want: map[string]string{"rarity": "mythic"}},
+		{name: "set code", query: card.NewQuery().SetCode("M11"), want: map[string]string{"setCode": "M11"}},
+		{name: "legal in", query: card.NewQuery().LegalIn("modern", "legacy"), want: map[string]string{"legalIn": "modern,legacy"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.query.Params(); !maps.Equal(got, test.want) {
+				t.Errorf("Params() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+/*
+countingTransport - An http.RoundTripper that counts the requests sent through it
+*/
+type countingTransport struct {
+	// requests - The number of requests sent
+	requests atomic.Int32
+}
+
+/*
+RoundTrip - Count the request and send it with http.DefaultTransport
+*/
+func (transport *countingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	transport.requests.Add(1)
+	return http.DefaultTransport.RoundTrip(request)
+}
+
+func TestSearch(t *testing.T) {
+	server := testserver.New(testserver.WithoutAuth())
+	t.Cleanup(server.Close)
+
+	for index, fixture := range []*cardModel.CardSet{bolt, helix, elves, golem, krenko} {
+		seeded := &cardModel.CardSet{
+			Name:        fixture.GetName(),
+			Colors:      fixture.GetColors(),
+			ManaValue:   fixture.GetManaValue(),
+			Types:       fixture.GetTypes(),
+			Identifiers: &cardModel.CardIdentifiers{MtgjsonV4Id: fmt.Sprintf("00000000-0000-0000-0000-%012d", index)},
+		}
+
+		if err := server.AddCard(seeded, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	transport := &countingTransport{}
+
+	httpClient, err := client.New(client.WithTransport(transport))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cardApi, err := card.New(server.URL(), httpClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for result, err := range cardApi.Search(context.Background(), card.NewQuery().Types("Creature").MaxManaValue(4), 1) {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		names = append(names, result.GetName())
+	}
+
+	if want := []string{"Llanowar Elves", "Krenko, Mob Boss"}; !slices.Equal(names, want) {
+		t.Errorf("Search() = %q, want %q", names, want)
+	}
+
+	// one page per matching card and a final empty page, rather than one page per card in the database
+	if got := transport.requests.Load(); got != 3 {
+		t.Errorf("requests = %d, want the server to only page through the matching cards", got)
+	}
+}
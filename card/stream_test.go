@@ -0,0 +1,94 @@
+package card_test
+
+import (
+	"context"
+	"fmt"
+	cardModel "github.com/stevezaluk/mtgjson-models/card"
+	"github.com/stevezaluk/mtgjson-sdk-client/card"
+	"github.com/stevezaluk/mtgjson-sdk-client/client"
+	"github.com/stevezaluk/mtgjson-sdk-client/testserver"
+	"testing"
+)
+
+/*
+benchmarkCards - The number of cards the benchmarks are run against
+*/
+const benchmarkCards = 5000
+
+/*
+newBenchmarkAPI - Start a testserver seeded with benchmarkCards cards and return a CardAPI that targets it
+*/
+func newBenchmarkAPI(b *testing.B) *card.CardAPI {
+	server := testserver.New(testserver.WithoutAuth())
+	b.Cleanup(server.Close)
+
+	for index := range benchmarkCards {
+		seeded := &cardModel.CardSet{
+			Name:     fmt.Sprintf("Card %d", index),
+			Type:     "Creature - Elf Druid",
+			ManaCost: "{1}{G}",
+			Text:     "{T}: Add {G}. Llanowar Elves can't be blocked except by two or more creatures.",
+			Identifiers: &cardModel.CardIdentifiers{
+				MtgjsonV4Id: fmt.Sprintf("00000000-0000-0000-0000-%012d", index),
+			},
+		}
+
+		if err := server.AddCard(seeded, ""); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	httpClient, err := client.New()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	cardApi, err := card.New(server.URL(), httpClient)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return cardApi
+}
+
+func BenchmarkIndexCards(b *testing.B) {
+	cardApi := newBenchmarkAPI(b)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for range b.N {
+		cards, err := cardApi.IndexCards(ctx, benchmarkCards, 0)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		if len(*cards) != benchmarkCards {
+			b.Fatalf("IndexCards() returned %d cards, want %d", len(*cards), benchmarkCards)
+		}
+	}
+}
+
+func BenchmarkStreamCards(b *testing.B) {
+	cardApi := newBenchmarkAPI(b)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for range b.N {
+		count := 0
+		for _, err := range cardApi.StreamCards(ctx, benchmarkCards, 0) {
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			count++
+		}
+
+		if count != benchmarkCards {
+			b.Fatalf("StreamCards() returned %d cards, want %d", count, benchmarkCards)
+		}
+	}
+}
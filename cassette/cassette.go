@@ -0,0 +1,100 @@
+package cassette
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+/*
+ErrInteractionNotFound - Returned by a Recorder in ModeReplay when the cassette holds no unused interaction matching the
+request
+*/
+var ErrInteractionNotFound = errors.New("cassette: no recorded interaction matches the request")
+
+/*
+RecordedRequest - An HTTP request as it is stored in a cassette
+*/
+type RecordedRequest struct {
+	// Method - The HTTP method of the request
+	Method string `json:"method"`
+
+	// URL - The full URL the request was sent to
+	URL string `json:"url"`
+
+	// Headers - The headers of the request, with sensitive values redacted
+	Headers http.Header `json:"headers,omitempty"`
+
+	// Body - The body of the request, with sensitive fields redacted
+	Body string `json:"body,omitempty"`
+}
+
+/*
+RecordedResponse - An HTTP response as it is stored in a cassette
+*/
+type RecordedResponse struct {
+	// StatusCode - The status code of the response
+	StatusCode int `json:"status_code"`
+
+	// Headers - The headers of the response, with sensitive values redacted
+	Headers http.Header `json:"headers,omitempty"`
+
+	// Body - The body of the response, with sensitive fields redacted
+	Body string `json:"body,omitempty"`
+}
+
+/*
+Interaction - A single request and the response that was returned for it
+*/
+type Interaction struct {
+	// Request - The request that was sent
+	Request RecordedRequest `json:"request"`
+
+	// Response - The response that was returned for the request
+	Response RecordedResponse `json:"response"`
+}
+
+/*
+Cassette - The interactions of a recorded session, in the order they were made. This is the structure of a fixture
+file
+*/
+type Cassette struct {
+	// Interactions - The recorded interactions
+	Interactions []*Interaction `json:"interactions"`
+}
+
+/*
+Load - Read a cassette from the fixture file at the path passed in the parameter
+*/
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return nil, err
+	}
+
+	return &cassette, nil
+}
+
+/*
+Save - Write the cassette as indented JSON to the fixture file at the path passed in the parameter, creating any
+missing parent directories
+*/
+func (cassette *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
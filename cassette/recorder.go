@@ -0,0 +1,285 @@
+package cassette
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+/*
+Mode - Controls whether a Recorder sends requests over the network or serves them from a cassette
+*/
+type Mode int
+
+const (
+	// ModeRecord - Send each request through the real transport and record the interaction
+	ModeRecord Mode = iota
+
+	// ModeReplay - Serve each request from the recorded interactions without touching the network
+	ModeReplay
+)
+
+/*
+Recorder - An http.RoundTripper that records the requests made by a client.HTTPClient to a cassette, or replays them
+from one. Pass it to client.WithTransport (or api.NewFromURL through its options) to use it:
+
+	recorder, err := cassette.New("testdata/deck.json", cassette.ModeReplay)
+	mtgjson, err := api.NewFromURL(stagingUrl, client.WithTransport(recorder))
+
+In ModeRecord, Save must be called once the session has finished for the cassette to be written. Bearer tokens,
+cookies, passwords and token sets are redacted before they are recorded
+*/
+type Recorder struct {
+	// mode - Whether the recorder is recording or replaying
+	mode Mode
+
+	// path - The path of the fixture file the cassette is loaded from and saved to
+	path string
+
+	// transport - The RoundTripper that requests are sent through in ModeRecord
+	transport http.RoundTripper
+
+	// redactedHeaders - The headers whose values are redacted
+	redactedHeaders []string
+
+	// redactedFields - The JSON body fields whose values are redacted
+	redactedFields []string
+
+	// cassette - The recorded interactions
+	cassette *Cassette
+
+	// used - Tracks which interactions have already been replayed, so that repeated identical requests are served
+	// their responses in the order they were recorded
+	used []bool
+
+	// mutex - Guards the cassette and the used interactions
+	mutex sync.Mutex
+}
+
+/*
+Option - Configures a Recorder when it is constructed with New
+*/
+type Option func(recorder *Recorder)
+
+/*
+WithTransport - Send requests through the RoundTripper passed in the parameter in ModeRecord. http.DefaultTransport is
+used if this is not set
+*/
+func WithTransport(transport http.RoundTripper) Option {
+	return func(recorder *Recorder) {
+		recorder.transport = transport
+	}
+}
+
+/*
+WithRedactedHeaders - Redact the headers passed in the parameter in addition to DefaultRedactedHeaders
+*/
+func WithRedactedHeaders(headers ...string) Option {
+	return func(recorder *Recorder) {
+		recorder.redactedHeaders = append(recorder.redactedHeaders, headers...)
+	}
+}
+
+/*
+WithRedactedFields - Redact the JSON body fields passed in the parameter in addition to DefaultRedactedFields
+*/
+func WithRedactedFields(fields ...string) Option {
+	return func(recorder *Recorder) {
+		recorder.redactedFields = append(recorder.redactedFields, fields...)
+	}
+}
+
+/*
+New - Create a new Recorder for the fixture file at the path passed in the parameter. In ModeReplay the cassette is
+loaded from the file immediately, and an error is returned if it cannot be read. In ModeRecord the recorder starts
+with an empty cassette that replaces the file when Save is called
+*/
+func New(path string, mode Mode, opts ...Option) (*Recorder, error) {
+	recorder := &Recorder{
+		mode:            mode,
+		path:            path,
+		transport:       http.DefaultTransport,
+		redactedHeaders: append([]string{}, DefaultRedactedHeaders...),
+		redactedFields:  append([]string{}, DefaultRedactedFields...),
+		cassette:        &Cassette{},
+	}
+
+	for _, opt := range opts {
+		opt(recorder)
+	}
+
+	switch mode {
+	case ModeRecord:
+	case ModeReplay:
+		cassette, err := Load(path)
+		if err != nil {
+			return nil, fmt.Errorf("cassette: failed to load %s: %w", path, err)
+		}
+
+		recorder.cassette = cassette
+		recorder.used = make([]bool, len(cassette.Interactions))
+	default:
+		return nil, fmt.Errorf("cassette: unknown mode %d", mode)
+	}
+
+	return recorder, nil
+}
+
+/*
+Mode - Returns whether the recorder is recording or replaying
+*/
+func (recorder *Recorder) Mode() Mode {
+	return recorder.mode
+}
+
+/*
+Save - Write the recorded interactions to the fixture file. Returns an error if the recorder is in ModeReplay
+*/
+func (recorder *Recorder) Save() error {
+	if recorder.mode != ModeRecord {
+		return errors.New("cassette: only a recorder in record mode can be saved")
+	}
+
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	return recorder.cassette.Save(recorder.path)
+}
+
+/*
+RoundTrip - Implements http.RoundTripper. Records the interaction in ModeRecord, or serves the first unused recorded
+interaction matching the request in ModeReplay
+*/
+func (recorder *Recorder) RoundTrip(request *http.Request) (*http.Response, error) {
+	body, err := readBody(&request.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if recorder.mode == ModeReplay {
+		return recorder.replay(request, body)
+	}
+
+	return recorder.record(request, body)
+}
+
+/*
+record - Send the request through the real transport and append the redacted interaction to the cassette
+*/
+func (recorder *Recorder) record(request *http.Request, body string) (*http.Response, error) {
+	outgoing := request.Clone(request.Context())
+	outgoing.Body = io.NopCloser(strings.NewReader(body))
+
+	resp, err := recorder.transport.RoundTrip(outgoing)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := readBody(&resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	respHeaders := redactHeaders(resp.Header, recorder.redactedHeaders)
+	respHeaders.Del("Content-Length") // redaction may change the length of the body
+
+	interaction := &Interaction{
+		Request: RecordedRequest{
+			Method:  request.Method,
+			URL:     request.URL.String(),
+			Headers: redactHeaders(request.Header, recorder.redactedHeaders),
+			Body:    redactBody(body, recorder.redactedFields),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Headers:    respHeaders,
+			Body:       redactBody(respBody, recorder.redactedFields),
+		},
+	}
+
+	recorder.mutex.Lock()
+	recorder.cassette.Interactions = append(recorder.cassette.Interactions, interaction)
+	recorder.mutex.Unlock()
+
+	return resp, nil
+}
+
+/*
+replay - Serve the first unused interaction that matches the request. Requests are matched on their method, path,
+query parameters and redacted body, but not on their host, so that a cassette recorded against one server can be
+replayed with a client pointed at any base URL
+*/
+func (recorder *Recorder) replay(request *http.Request, body string) (*http.Response, error) {
+	body = redactBody(body, recorder.redactedFields)
+
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	for index, interaction := range recorder.cassette.Interactions {
+		if recorder.used[index] || !matches(interaction, request, body) {
+			continue
+		}
+
+		recorder.used[index] = true
+
+		header := interaction.Response.Headers.Clone()
+		if header == nil {
+			header = make(http.Header)
+		}
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(strings.NewReader(interaction.Response.Body)),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       request,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s %s", ErrInteractionNotFound, request.Method, request.URL.RequestURI())
+}
+
+/*
+matches - Returns true if the recorded interaction was made for the request passed in the parameter
+*/
+func matches(interaction *Interaction, request *http.Request, body string) bool {
+	if interaction.Request.Method != request.Method || interaction.Request.Body != body {
+		return false
+	}
+
+	recorded, err := url.Parse(interaction.Request.URL)
+	if err != nil {
+		return false
+	}
+
+	return recorded.Path == request.URL.Path && recorded.Query().Encode() == request.URL.Query().Encode()
+}
+
+/*
+readBody - Read the body passed in the parameter in full and replace it with a fresh reader over the same bytes, so
+that it can still be consumed by the caller
+*/
+func readBody(body *io.ReadCloser) (string, error) {
+	if *body == nil || *body == http.NoBody {
+		return "", nil
+	}
+
+	data, err := io.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return "", err
+	}
+
+	*body = io.NopCloser(bytes.NewReader(data))
+
+	return string(data), nil
+}
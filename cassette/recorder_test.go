@@ -0,0 +1,87 @@
+package cassette_test
+
+import (
+	"context"
+	"errors"
+	"github.com/stevezaluk/mtgjson-sdk-client/api"
+	"github.com/stevezaluk/mtgjson-sdk-client/cassette"
+	"github.com/stevezaluk/mtgjson-sdk-client/client"
+	"github.com/stevezaluk/mtgjson-sdk-client/testserver"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "session.json")
+
+	server := testserver.New()
+	t.Cleanup(server.Close)
+
+	if err := server.AddUser("user@example.com", "user", "password123"); err != nil {
+		t.Fatal(err)
+	}
+
+	recorder, err := cassette.New(path, cassette.ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recording, err := api.NewFromURL(server.URL(), client.WithTransport(recorder))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := recording.SetEmailPasswordAuth(ctx, "user@example.com", "password123"); err != nil {
+		t.Fatal(err)
+	}
+
+	accessToken := recording.Client().BearerToken().AccessToken
+	if _, err := recording.User.GetUser(ctx, "user@example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, secret := range []string{"password123", accessToken} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("the cassette contains the secret %q", secret)
+		}
+	}
+
+	replayer, err := cassette.New(path, cassette.ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replaying, err := api.NewFromURL("http://replay.invalid", client.WithTransport(replayer))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := replaying.SetEmailPasswordAuth(ctx, "user@example.com", "password123"); err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := replaying.User.GetUser(ctx, "user@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if user.GetUsername() != "user" {
+		t.Errorf("replayed username = %q, want user", user.GetUsername())
+	}
+
+	if _, err := replaying.User.GetUser(ctx, "user@example.com"); !errors.Is(err, cassette.ErrInteractionNotFound) {
+		t.Errorf("GetUser() once the interactions are used up error = %v, want ErrInteractionNotFound", err)
+	}
+}
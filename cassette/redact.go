@@ -0,0 +1,88 @@
+package cassette
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+)
+
+/*
+Redacted - The value that sensitive headers and fields are replaced with before they are written to a cassette
+*/
+const Redacted = "[REDACTED]"
+
+/*
+DefaultRedactedHeaders - The headers whose values are redacted from each request and response
+*/
+var DefaultRedactedHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+/*
+DefaultRedactedFields - The JSON fields whose values are redacted from each request and response body. These cover the
+password sent to /login and /register, and the token set returned from /login and /refresh
+*/
+var DefaultRedactedFields = []string{"password", "access_token", "refresh_token", "id_token"}
+
+/*
+redactHeaders - Returns a copy of the headers passed in the parameter with the values of the named headers replaced
+with Redacted
+*/
+func redactHeaders(headers http.Header, names []string) http.Header {
+	redacted := headers.Clone()
+
+	for _, name := range names {
+		if _, ok := redacted[http.CanonicalHeaderKey(name)]; ok {
+			redacted.Set(name, Redacted)
+		}
+	}
+
+	return redacted
+}
+
+/*
+redactBody - Returns the body passed in the parameter with the values of the named JSON fields replaced with Redacted,
+at any depth. Bodies that are not JSON are returned as is
+*/
+func redactBody(body string, fields []string) string {
+	var value any
+	if body == "" || json.Unmarshal([]byte(body), &value) != nil {
+		return body
+	}
+
+	if !redactValue(value, fields) {
+		return body
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return body
+	}
+
+	return string(data)
+}
+
+/*
+redactValue - Replace the values of the named fields within a decoded JSON value in place. Returns true if any field
+was redacted
+*/
+func redactValue(value any, fields []string) bool {
+	redacted := false
+
+	switch typed := value.(type) {
+	case map[string]any:
+		for key, child := range typed {
+			if slices.Contains(fields, key) {
+				typed[key] = Redacted
+				redacted = true
+				continue
+			}
+
+			redacted = redactValue(child, fields) || redacted
+		}
+	case []any:
+		for _, child := range typed {
+			redacted = redactValue(child, fields) || redacted
+		}
+	}
+
+	return redacted
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-resty/resty/v2"
+	apiModels "github.com/stevezaluk/mtgjson-models/api"
+	"net/url"
+)
+
+/*
+RequestIDHeader - The header used for correlating a request made by the SDK with the logs of the API server
+*/
+const RequestIDHeader = "X-Request-ID"
+
+/*
+ErrUnexpectedStatus - Returned (wrapped in an APIError) when the API responds with a non-2xx status code that
+the endpoint does not map to a more specific error
+*/
+var ErrUnexpectedStatus = errors.New("client: unexpected status code returned from the API")
+
+/*
+ErrConflict - Returned (wrapped in an APIError) when the API rejects an update with a 412 because the model was
+modified after the copy that the update was based on was read. Fetch the model again and reapply the change
+*/
+var ErrConflict = errors.New("client: the object was modified since it was read")
+
+/*
+APIError - An error returned by the MTGJSON API. It wraps one of the sentinel errors from mtgjson-models/errors,
+so that errors.Is continues to work, while also exposing the details of the failed request
+*/
+type APIError struct {
+	// Err - The sentinel error that the status code and response were mapped to
+	Err error
+
+	// StatusCode - The HTTP status code returned by the API
+	StatusCode int
+
+	// Response - The decoded APIResponse returned by the API. This may be nil if the body could not be decoded
+	Response *apiModels.APIResponse
+
+	// Method - The HTTP method of the failed request
+	Method string
+
+	// Path - The URL path of the failed request
+	Path string
+
+	// RequestID - The ID used for correlating the request with the logs of the API server
+	RequestID string
+}
+
+/*
+NewAPIError - Construct a new APIError from a resty response and the sentinel error that it maps to
+*/
+func NewAPIError(resp *resty.Response, err error) *APIError {
+	apiError := &APIError{
+		Err:        err,
+		StatusCode: resp.StatusCode(),
+	}
+
+	if response, ok := resp.Error().(*apiModels.APIResponse); ok {
+		apiError.Response = response
+	}
+
+	if resp.Request != nil {
+		apiError.Method = resp.Request.Method
+		apiError.RequestID = resp.Request.Header.Get(RequestIDHeader)
+
+		if requestUrl, err := url.Parse(resp.Request.URL); err == nil {
+			apiError.Path = requestUrl.Path
+		}
+	}
+
+	if requestId := resp.Header().Get(RequestIDHeader); requestId != "" {
+		apiError.RequestID = requestId
+	}
+
+	return apiError
+}
+
+/*
+Error - Returns a string representation of the error, including the request and the message returned by the server
+*/
+func (err *APIError) Error() string {
+	message := fmt.Sprintf("mtgjson: %s %s returned %d (request id: %s): %v", err.Method, err.Path, err.StatusCode, err.RequestID, err.Err)
+
+	if err.Response != nil && err.Response.Message != "" {
+		message += ": " + err.Response.Message
+	}
+
+	return message
+}
+
+/*
+Unwrap - Returns the sentinel error wrapped by the APIError
+*/
+func (err *APIError) Unwrap() error {
+	return err.Err
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"github.com/go-resty/resty/v2"
+	apiModels "github.com/stevezaluk/mtgjson-models/api"
+	sdkErrors "github.com/stevezaluk/mtgjson-models/errors"
+	"net/http"
+)
+
+/*
+StatusError - Describes how a single HTTP status code returned by an endpoint is mapped to a sentinel error
+*/
+type StatusError struct {
+	// Match - Sentinel errors that are returned when their message equals the Err field of the APIResponse
+	Match []error
+
+	// Default - The error returned when the APIResponse does not match any error in Match. If this is nil then ErrUnexpectedStatus is returned
+	Default error
+}
+
+/*
+ErrorMap - Maps the HTTP status codes returned by a single endpoint to the sentinel errors that they represent
+*/
+type ErrorMap map[int]StatusError
+
+/*
+CommonErrors - The status codes that every endpoint of the API shares. These are used whenever the ErrorMap of an
+endpoint does not provide its own mapping for the status code
+*/
+var CommonErrors = ErrorMap{
+	http.StatusUnauthorized:       {Default: sdkErrors.ErrTokenInvalid},
+	http.StatusForbidden:          {Default: sdkErrors.ErrInvalidPermissions},
+	http.StatusPreconditionFailed: {Default: ErrConflict},
+}
+
+/*
+Resolve - Returns the sentinel error that the status code and APIResponse map to. Falls back to CommonErrors and then to
+ErrUnexpectedStatus if the status code is not mapped
+*/
+func (errorMap ErrorMap) Resolve(statusCode int, response *apiModels.APIResponse) error {
+	statusError, ok := errorMap[statusCode]
+	if !ok {
+		statusError, ok = CommonErrors[statusCode]
+		if !ok {
+			return ErrUnexpectedStatus
+		}
+	}
+
+	if response != nil {
+		for _, err := range statusError.Match {
+			if response.Err == err.Error() {
+				return err
+			}
+		}
+	}
+
+	if statusError.Default == nil {
+		return ErrUnexpectedStatus
+	}
+
+	return statusError.Default
+}
+
+/*
+Execute - Executes a request built with HTTPClient.BuildRequest and decodes the response body into a new instance of T.
+Any non-2xx response is converted into an APIError using the ErrorMap of the endpoint, so a successful call always
+returns a populated result and a failed call always returns a nil result. If the request fails with a 401 and the
+client that built it holds a refresh token, the access token is refreshed and the request is retried once
+*/
+func Execute[T any](request *resty.Request, method string, url string, errorMap ErrorMap) (*T, error) {
+	result := new(T)
+
+	resp, err := send(request.SetResult(result), method, url)
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.IsSuccess() {
+		response, _ := resp.Error().(*apiModels.APIResponse)
+		return nil, NewAPIError(resp, errorMap.Resolve(resp.StatusCode(), response))
+	}
+
+	return result, nil
+}
+
+/*
+send - Executes the request, refreshing the access token and retrying the request once if it fails with a 401 and the
+client that built it holds a refresh token. The body of the rejected response is closed before the request is retried
+*/
+func send(request *resty.Request, method string, url string) (*resty.Response, error) {
+	resp, err := request.Execute(method, url)
+	if err != nil {
+		closeBody(resp)
+		return nil, err
+	}
+
+	client := clientOf(request)
+	if resp.StatusCode() == http.StatusUnauthorized && client != nil && client.canRefresh(request) {
+		if client.refreshToken(request.Context(), request.Token) == nil {
+			closeBody(resp)
+			request.Attempt = 0
+
+			resp, err = request.Execute(method, url)
+			if err != nil {
+				closeBody(resp)
+				return nil, err
+			}
+		}
+	}
+
+	return resp, nil
+}
@@ -1,9 +1,18 @@
 package client
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
 	"github.com/auth0/go-auth0/authentication/oauth"
 	"github.com/go-resty/resty/v2"
 	apiModels "github.com/stevezaluk/mtgjson-models/api"
+	"math"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 /*
@@ -16,16 +25,135 @@ type HTTPClient struct {
 
 	// token - The JWT Token Set used for authentication
 	token *oauth.TokenSet
+
+	// tokenExpiry - The time at which the access token expires. This is zero if the token set did not provide an expiry
+	tokenExpiry time.Time
+
+	// tokenStore - The store the token is persisted to, so that it can be reused across runs
+	tokenStore TokenStore
+
+	// refresher - The function used for exchanging the refresh token for a new token set
+	refresher RefreshFunc
+
+	// storeErrorHandler - Receives errors from the token store while saving a refreshed token
+	storeErrorHandler StoreErrorFunc
+
+	// tokenMutex - Guards the token, its expiry, the token store, the refresher and the store error handler so that the client can be shared between goroutines
+	tokenMutex sync.RWMutex
+
+	// refreshMutex - Serializes token refreshes so that concurrent requests only refresh the token once
+	refreshMutex sync.Mutex
+
+	// retryPolicy - The policy used for retrying requests that fail with a transient error. It is read on every
+	// attempt, so it can be replaced while requests are in flight
+	retryPolicy atomic.Pointer[RetryPolicy]
+
+	// userAgent - The User-Agent header sent with each request
+	userAgent string
 }
 
 /*
 New Constructor function for building a new HTTP Client. This should get called once
-and then passed between each namespace of the API
+and then passed between each namespace of the API. The client is created with the
+DefaultRetryPolicy, which only retries idempotent read requests, and can be further
+configured with the options passed in the parameter
 */
-func New() *HTTPClient {
-	return &HTTPClient{
-		client: resty.New(),
+func New(opts ...Option) (*HTTPClient, error) {
+	settings := &options{
+		userAgent:   DefaultUserAgent,
+		retryPolicy: DefaultRetryPolicy(),
+	}
+
+	for _, opt := range opts {
+		if err := opt(settings); err != nil {
+			return nil, err
+		}
 	}
+
+	restyClient := resty.New()
+	if settings.httpClient != nil {
+		httpClient := *settings.httpClient // resty sets the timeout and transport on the client it wraps, so wrap a copy
+		restyClient = resty.NewWithClient(&httpClient)
+	}
+
+	if settings.transport != nil {
+		restyClient.SetTransport(settings.transport)
+	}
+
+	if settings.proxy != nil || settings.tlsConfig != nil {
+		transport, err := restyClient.Transport()
+		if err != nil {
+			return nil, fmt.Errorf("client: unable to configure the proxy or TLS config: %w", err)
+		}
+
+		transport = transport.Clone() // the transport may be shared, such as http.DefaultTransport
+		restyClient.SetTransport(transport)
+
+		if settings.proxy != nil {
+			transport.Proxy = http.ProxyURL(settings.proxy)
+		}
+
+		if settings.tlsConfig != nil {
+			transport.TLSClientConfig = settings.tlsConfig
+		}
+	}
+
+	if settings.timeout > 0 {
+		restyClient.SetTimeout(settings.timeout)
+	}
+
+	restyClient.SetHeaders(settings.headers)
+
+	client := &HTTPClient{
+		client:    restyClient,
+		userAgent: settings.userAgent,
+	}
+
+	// resty reads its retry settings without synchronization, so they are set once here and the retry policy is
+	// applied by the callbacks instead, which load it on every attempt
+	client.client.
+		SetRetryCount(math.MaxInt32).
+		SetRetryWaitTime(0).
+		SetRetryMaxWaitTime(math.MaxInt64).
+		AddRetryCondition(func(resp *resty.Response, err error) bool {
+			policy := client.retryPolicy.Load()
+			if policy == nil || resp == nil || resp.Request.Attempt >= policy.MaxAttempts {
+				return false
+			}
+
+			retry := policy.shouldRetry(resp, err)
+			if retry {
+				// resty leaves the body of a streamed response open when it is discarded for a retry. On the
+				// last attempt the response is returned to the caller instead, so its body must stay readable
+				closeBody(resp)
+			}
+
+			return retry
+		}).
+		SetRetryAfter(func(_ *resty.Client, resp *resty.Response) (time.Duration, error) {
+			policy := client.retryPolicy.Load()
+			if policy == nil {
+				return 0, nil
+			}
+
+			delay := policy.backoff(resp)
+			if policy.MaxDelay > 0 {
+				delay = min(delay, policy.MaxDelay)
+			}
+
+			return delay, nil
+		})
+
+	client.client.OnBeforeRequest(client.authMiddleware)
+
+	client.SetRetryPolicy(settings.retryPolicy)
+	client.SetStoreErrorHandler(settings.storeErrorHandler)
+
+	if err := client.SetTokenStore(settings.tokenStore); err != nil {
+		return nil, err
+	}
+
+	return client, nil
 }
 
 /*
@@ -36,30 +164,45 @@ func (client *HTTPClient) Client() *resty.Client {
 }
 
 /*
-SetBearerToken - Sets the authentication token for the current session
+httpClientKey - Context key used to find the HTTPClient that built a request, so that Execute and Stream can refresh
+its token
 */
-func (client *HTTPClient) SetBearerToken(token *oauth.TokenSet) {
-	if token == nil {
-		return
-	}
-
-	client.token = token
-
-}
+type httpClientKey struct{}
 
 /*
-BuildRequest Builds a new resty request automatically, filling in the headers and the authentication token
+BuildRequest Builds a new resty request automatically, filling in the headers. The authentication token is attached
+to each attempt of the request by the client, refreshing it first if it is about to expire. The
+context passed in the parameter is attached to the request so that cancellation and deadlines are honored. Each
+request is tagged with a random request ID that is reported back in any APIError. The request should be passed
+to Execute, which decodes the result and maps any error returned by the API. Replacing the context of the request
+prevents Execute from refreshing the token when the request is rejected with a 401
 */
-func (client *HTTPClient) BuildRequest(result interface{}) *resty.Request {
+func (client *HTTPClient) BuildRequest(ctx context.Context) *resty.Request {
 	request := client.Client().R().
+		SetContext(context.WithValue(ctx, httpClientKey{}, client)).
 		SetHeader("Accept", "application/json").
-		SetHeader("User-Agent", "MTGJSON-SDK-Client v1.0.0").
-		SetResult(result).
+		SetHeader(RequestIDHeader, newRequestId()).
+		SetHeader("User-Agent", client.userAgent).
 		SetError(&apiModels.APIResponse{})
 
-	if client.token != nil {
-		request.SetAuthToken(client.token.AccessToken)
-	}
-
 	return request
 }
+
+/*
+clientOf - Returns the HTTPClient that built the request passed in the parameter, or nil if it was not built with
+BuildRequest
+*/
+func clientOf(request *resty.Request) *HTTPClient {
+	client, _ := request.Context().Value(httpClientKey{}).(*HTTPClient)
+	return client
+}
+
+/*
+newRequestId - Generate a random hex encoded ID used for correlating a request with the logs of the API server
+*/
+func newRequestId() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf) // crypto/rand.Read never returns an error
+
+	return hex.EncodeToString(buf)
+}
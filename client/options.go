@@ -0,0 +1,186 @@
+package client
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+/*
+DefaultUserAgent - The User-Agent header sent with each request unless WithUserAgent is used
+*/
+const DefaultUserAgent = "MTGJSON-SDK-Client v1.0.0"
+
+/*
+options - The settings collected from each Option passed to New
+*/
+type options struct {
+	// httpClient - The *http.Client that resty wraps. If nil, resty creates its own
+	httpClient *http.Client
+
+	// transport - The RoundTripper used for making HTTP requests
+	transport http.RoundTripper
+
+	// timeout - The timeout applied to each request attempt
+	timeout time.Duration
+
+	// userAgent - The User-Agent header sent with each request
+	userAgent string
+
+	// headers - Default headers sent with each request
+	headers map[string]string
+
+	// proxy - The URL of the proxy that requests are sent through
+	proxy *url.URL
+
+	// tlsConfig - The TLS configuration of the transport
+	tlsConfig *tls.Config
+
+	// retryPolicy - The policy used for retrying requests that fail with a transient error
+	retryPolicy *RetryPolicy
+
+	// tokenStore - The store the token is persisted to
+	tokenStore TokenStore
+
+	// storeErrorHandler - Receives errors from the token store while saving a refreshed token
+	storeErrorHandler StoreErrorFunc
+}
+
+/*
+Option - Configures an HTTPClient when it is constructed with New. Options are applied in order, so a later option
+overrides an earlier one
+*/
+type Option func(opts *options) error
+
+/*
+WithHTTPClient - Use a custom *http.Client for making requests. The client is copied, so its settings such as the
+timeout and cookie jar are used but never modified. Options that modify the transport (WithProxy and WithTLSConfig)
+are applied to a copy of its transport
+*/
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(opts *options) error {
+		if httpClient == nil {
+			return errors.New("client: http client must not be nil")
+		}
+
+		opts.httpClient = httpClient
+		return nil
+	}
+}
+
+/*
+WithTransport - Use a custom http.RoundTripper for making requests. WithProxy and WithTLSConfig can only be combined
+with this option if the transport is an *http.Transport, in which case they are applied to a copy of it
+*/
+func WithTransport(transport http.RoundTripper) Option {
+	return func(opts *options) error {
+		if transport == nil {
+			return errors.New("client: transport must not be nil")
+		}
+
+		opts.transport = transport
+		return nil
+	}
+}
+
+/*
+WithTimeout - Set the timeout applied to each request attempt. Use a context deadline to bound the total time
+spent on a call including its retries
+*/
+func WithTimeout(timeout time.Duration) Option {
+	return func(opts *options) error {
+		if timeout < 0 {
+			return fmt.Errorf("client: timeout must not be negative, got %s", timeout)
+		}
+
+		opts.timeout = timeout
+		return nil
+	}
+}
+
+/*
+WithUserAgent - Override the User-Agent header sent with each request
+*/
+func WithUserAgent(userAgent string) Option {
+	return func(opts *options) error {
+		opts.userAgent = userAgent
+		return nil
+	}
+}
+
+/*
+WithHeader - Add a default header that is sent with each request. This can be passed multiple times
+*/
+func WithHeader(key string, value string) Option {
+	return func(opts *options) error {
+		if opts.headers == nil {
+			opts.headers = make(map[string]string)
+		}
+
+		opts.headers[key] = value
+		return nil
+	}
+}
+
+/*
+WithProxy - Send each request through the proxy at the URL passed in the parameter
+*/
+func WithProxy(proxyUrl string) Option {
+	return func(opts *options) error {
+		parsed, err := url.Parse(proxyUrl)
+		if err != nil {
+			return fmt.Errorf("client: invalid proxy URL: %w", err)
+		}
+
+		if parsed.Scheme == "" || parsed.Host == "" {
+			return fmt.Errorf("client: invalid proxy URL %q, a scheme and host are required", proxyUrl)
+		}
+
+		opts.proxy = parsed
+		return nil
+	}
+}
+
+/*
+WithTLSConfig - Set the TLS configuration of the transport, for example to trust a private CA
+*/
+func WithTLSConfig(config *tls.Config) Option {
+	return func(opts *options) error {
+		opts.tlsConfig = config
+		return nil
+	}
+}
+
+/*
+WithRetryPolicy - Replace the DefaultRetryPolicy. Passing nil disables retries
+*/
+func WithRetryPolicy(policy *RetryPolicy) Option {
+	return func(opts *options) error {
+		opts.retryPolicy = policy
+		return nil
+	}
+}
+
+/*
+WithTokenStore - Persist the token of the client to the store passed in the parameter, see HTTPClient.SetTokenStore
+*/
+func WithTokenStore(store TokenStore) Option {
+	return func(opts *options) error {
+		opts.tokenStore = store
+		return nil
+	}
+}
+
+/*
+WithStoreErrorHandler - Pass errors from the TokenStore while saving a refreshed token to the handler passed in the
+parameter, see HTTPClient.SetStoreErrorHandler
+*/
+func WithStoreErrorHandler(handler StoreErrorFunc) Option {
+	return func(opts *options) error {
+		opts.storeErrorHandler = handler
+		return nil
+	}
+}
@@ -0,0 +1,56 @@
+package client
+
+import (
+	"crypto/tls"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestOptionsDoNotModifyCallerValues(t *testing.T) {
+	transport := &http.Transport{}
+	httpClient := &http.Client{Transport: transport}
+
+	tests := []struct {
+		name   string
+		option Option
+	}{
+		{name: "http client", option: WithHTTPClient(httpClient)},
+		{name: "transport", option: WithTransport(transport)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, err := New(
+				test.option,
+				WithTimeout(5*time.Second),
+				WithProxy("http://proxy.example.com:8080"),
+				WithTLSConfig(&tls.Config{ServerName: "example.com"}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if httpClient.Timeout != 0 {
+				t.Errorf("timeout of the caller's client = %s, want it unchanged", httpClient.Timeout)
+			}
+
+			if transport.Proxy != nil || transport.TLSClientConfig.ServerName != "" { // Clone sets up HTTP/2 on the original, which fills in its TLS config
+				t.Error("the caller's transport was modified")
+			}
+
+			configured, err := client.Client().Transport()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if configured.Proxy == nil || configured.TLSClientConfig.ServerName != "example.com" {
+				t.Error("the proxy and TLS config were not applied to the client")
+			}
+
+			if client.Client().GetClient().Timeout != 5*time.Second {
+				t.Errorf("timeout = %s, want 5s", client.Client().GetClient().Timeout)
+			}
+		})
+	}
+}
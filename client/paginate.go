@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"strconv"
+)
+
+/*
+DefaultPageSize - The number of items fetched per request by the paginated iterators when a page size of 0 or less
+is passed
+*/
+const DefaultPageSize = 100
+
+/*
+PageFunc - Fetches a single page of at most limit items, starting at offset
+*/
+type PageFunc[T any] func(ctx context.Context, limit int, offset int) (*[]*T, error)
+
+/*
+Paginate - Returns an iterator that fetches pages lazily with the PageFunc passed in the parameter, yielding each item
+in order. Iteration stops once a page is shorter than the page size, or once fetching a page returns the error passed
+in the done parameter (the "no results" error of the endpoint, which the API returns for an offset past the last
+item). Any other error, including the error of a cancelled context, is yielded once with a nil item and ends the
+iteration
+*/
+func Paginate[T any](ctx context.Context, pageSize int, done error, fetch PageFunc[T]) iter.Seq2[*T, error] {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+
+	return func(yield func(*T, error) bool) {
+		for offset := 0; ; {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+
+			page, err := fetch(ctx, pageSize, offset)
+			if errors.Is(err, done) {
+				return
+			}
+
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			for _, item := range *page {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			if len(*page) < pageSize {
+				return
+			}
+
+			offset += len(*page)
+		}
+	}
+}
+
+/*
+PageParams - Returns the limit and offset query parameters for a paginated request. Parameters that are 0 or less are
+omitted, so that the API falls back to its own defaults
+*/
+func PageParams(limit int, offset int) map[string]string {
+	params := make(map[string]string)
+
+	if limit > 0 {
+		params["limit"] = strconv.Itoa(limit)
+	}
+
+	if offset > 0 {
+		params["offset"] = strconv.Itoa(offset)
+	}
+
+	return params
+}
@@ -0,0 +1,134 @@
+package client
+
+import (
+	"github.com/go-resty/resty/v2"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+RetryPolicy - Describes how the HTTPClient retries requests that fail with a transient error. Requests are
+only retried if both their HTTP method and the returned status code (or a connection error) are considered
+retryable by the policy
+*/
+type RetryPolicy struct {
+	// MaxAttempts - The total number of attempts made for a single request, including the first one. A value of 1 or less disables retries
+	MaxAttempts int
+
+	// BaseDelay - The delay before the first retry. Each following retry doubles the delay
+	BaseDelay time.Duration
+
+	// MaxDelay - The upper bound for the delay between two attempts, including delays requested with a Retry-After
+	// header. A value of 0 or less leaves the delay unbounded
+	MaxDelay time.Duration
+
+	// Jitter - The fraction (0.0 - 1.0) of each delay that is randomized to avoid retrying in lockstep with other clients
+	Jitter float64
+
+	// RetryableStatusCodes - The HTTP status codes that are considered transient and can be retried
+	RetryableStatusCodes []int
+
+	// RetryableMethods - The HTTP methods that are allowed to be retried. Mutation methods (POST, DELETE) must be opted into explicitly
+	RetryableMethods []string
+}
+
+/*
+DefaultRetryPolicy - Returns the retry policy used by a new HTTPClient. Only idempotent read requests (GET and HEAD)
+are retried, and only for connection errors and 429, 502, 503 and 504 responses
+*/
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   100 * time.Millisecond,
+		MaxDelay:    2 * time.Second,
+		Jitter:      0.5,
+		RetryableStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		RetryableMethods: []string{http.MethodGet, http.MethodHead},
+	}
+}
+
+/*
+AllowMethods - Opt additional HTTP methods into being retried. Use this to allow retrying the POST and DELETE calls
+made by the card, deck and set namespaces. Returns the policy so that calls can be chained
+*/
+func (policy *RetryPolicy) AllowMethods(methods ...string) *RetryPolicy {
+	for _, method := range methods {
+		method = strings.ToUpper(method)
+		if !slices.Contains(policy.RetryableMethods, method) {
+			policy.RetryableMethods = append(policy.RetryableMethods, method)
+		}
+	}
+
+	return policy
+}
+
+/*
+shouldRetry - Determines if a request should be retried according to the policy. Connection errors are retried
+for retryable methods, as are responses with a retryable status code
+*/
+func (policy *RetryPolicy) shouldRetry(resp *resty.Response, err error) bool {
+	if resp == nil || resp.Request == nil {
+		return false
+	}
+
+	if !slices.Contains(policy.RetryableMethods, strings.ToUpper(resp.Request.Method)) {
+		return false
+	}
+
+	if err != nil {
+		return true
+	}
+
+	return slices.Contains(policy.RetryableStatusCodes, resp.StatusCode())
+}
+
+/*
+backoff - Calculates the delay before the next attempt using exponential backoff with jitter. If the server
+sent a Retry-After header (in seconds) then it takes precedence over the calculated delay, although the client still
+limits it to the MaxDelay of the policy
+*/
+func (policy *RetryPolicy) backoff(resp *resty.Response) time.Duration {
+	if resp.RawResponse != nil {
+		if seconds, err := strconv.Atoi(resp.Header().Get("Retry-After")); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+
+	attempt := max(resp.Request.Attempt, 1)
+
+	delay := float64(policy.BaseDelay) * math.Pow(2, float64(attempt-1))
+	if policy.MaxDelay > 0 && delay > float64(policy.MaxDelay) {
+		delay = float64(policy.MaxDelay)
+	}
+
+	jitter := min(max(policy.Jitter, 0), 1)
+	delay = delay*(1-jitter) + rand.Float64()*delay*jitter
+
+	return time.Duration(delay)
+}
+
+/*
+SetRetryPolicy - Replace the retry policy used by the client. Passing nil disables retries entirely. This is safe to
+call while requests are in flight: each attempt uses the policy that is set when it completes. The policy must not
+be modified after it is passed in
+*/
+func (client *HTTPClient) SetRetryPolicy(policy *RetryPolicy) {
+	client.retryPolicy.Store(policy)
+}
+
+/*
+RetryPolicy - Returns the retry policy currently used by the client. Returns nil if retries are disabled
+*/
+func (client *HTTPClient) RetryPolicy() *RetryPolicy {
+	return client.retryPolicy.Load()
+}
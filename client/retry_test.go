@@ -0,0 +1,177 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"github.com/go-resty/resty/v2"
+	apiModels "github.com/stevezaluk/mtgjson-models/api"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	policy := &RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	tests := []struct {
+		name       string
+		attempt    int
+		retryAfter string
+		want       time.Duration
+	}{
+		{name: "first attempt", attempt: 1, want: 100 * time.Millisecond},
+		{name: "doubles", attempt: 3, want: 400 * time.Millisecond},
+		{name: "capped at max delay", attempt: 10, want: time.Second},
+		{name: "retry after", attempt: 1, retryAfter: "3", want: 3 * time.Second},
+		{name: "invalid retry after", attempt: 2, retryAfter: "soon", want: 200 * time.Millisecond},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			header := http.Header{}
+			if test.retryAfter != "" {
+				header.Set("Retry-After", test.retryAfter)
+			}
+
+			resp := &resty.Response{
+				Request:     &resty.Request{Attempt: test.attempt},
+				RawResponse: &http.Response{Header: header},
+			}
+
+			if got := policy.backoff(resp); got != test.want {
+				t.Errorf("backoff() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSetRetryPolicyUnboundedMaxDelay(t *testing.T) {
+	client, err := New(WithRetryPolicy(&RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := client.Client().RetryMaxWaitTime; got != math.MaxInt64 {
+		t.Errorf("RetryMaxWaitTime = %s, want an unbounded wait", got)
+	}
+}
+
+func TestRetryTransientStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		status   int
+		attempts int32
+	}{
+		{name: "get is retried", method: http.MethodGet, status: http.StatusServiceUnavailable, attempts: 3},
+		{name: "post is not retried", method: http.MethodPost, status: http.StatusServiceUnavailable, attempts: 1},
+		{name: "not found is not retried", method: http.MethodGet, status: http.StatusNotFound, attempts: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var hits atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+				hits.Add(1)
+				writer.WriteHeader(test.status)
+			}))
+			defer server.Close()
+
+			policy := DefaultRetryPolicy()
+			policy.BaseDelay = time.Millisecond
+			policy.MaxDelay = time.Millisecond
+
+			client, err := New(WithRetryPolicy(policy))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := client.BuildRequest(context.Background()).Execute(test.method, server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.StatusCode() != test.status {
+				t.Errorf("status = %d, want %d", resp.StatusCode(), test.status)
+			}
+
+			if got := hits.Load(); got != test.attempts {
+				t.Errorf("attempts = %d, want %d", got, test.attempts)
+			}
+		})
+	}
+}
+
+func TestStreamKeepsLastAttemptBody(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		hits.Add(1)
+		writer.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = writer.Write([]byte(`{"message":"unavailable"}`))
+	}))
+	defer server.Close()
+
+	policy := DefaultRetryPolicy()
+	policy.BaseDelay = time.Millisecond
+	policy.MaxDelay = time.Millisecond
+
+	client, err := New(WithRetryPolicy(policy))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Stream(client.BuildRequest(context.Background()), http.MethodGet, server.URL, nil, func(*apiModels.APIResponse) error {
+		return nil
+	})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Stream() error = %v, want an APIError", err)
+	}
+
+	if apiErr.Response.Message != "unavailable" {
+		t.Errorf("message = %q, want the body of the last attempt to be decoded", apiErr.Response.Message)
+	}
+
+	if hits.Load() != int32(policy.MaxAttempts) {
+		t.Errorf("attempts = %d, want %d", hits.Load(), policy.MaxAttempts)
+	}
+}
+
+func TestSetRetryPolicyWhileInFlight(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	policy := DefaultRetryPolicy()
+	policy.BaseDelay = time.Millisecond
+	policy.MaxDelay = time.Millisecond
+
+	client, err := New(WithRetryPolicy(policy))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		for range 20 {
+			if _, err := client.BuildRequest(context.Background()).Execute(http.MethodGet, server.URL); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+
+	for range 20 {
+		client.SetRetryPolicy(nil)
+		client.SetRetryPolicy(policy)
+	}
+
+	<-done
+}
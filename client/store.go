@@ -0,0 +1,304 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/auth0/go-auth0/authentication/oauth"
+	"github.com/mitchellh/go-homedir"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+/*
+DefaultTokenPath - The path used by the FileTokenStore if no path is provided
+*/
+const DefaultTokenPath = "~/.mtgjson/token.json"
+
+/*
+ErrNoStoredToken - Returned by a TokenStore when no token has been saved yet
+*/
+var ErrNoStoredToken = errors.New("client: no token has been saved in the token store")
+
+/*
+StoredToken - A token set persisted by a TokenStore along with the time at which its access token expires
+*/
+type StoredToken struct {
+	// Token - The token set returned from AuthAPI.Login or AuthAPI.RefreshToken
+	Token *oauth.TokenSet `json:"token"`
+
+	// Expiry - The time at which the access token expires. This is zero if the token set did not provide an expiry
+	Expiry time.Time `json:"expiry"`
+}
+
+/*
+Expired - Returns true if the access token of the stored token has expired
+*/
+func (token *StoredToken) Expired() bool {
+	return !token.Expiry.IsZero() && time.Now().After(token.Expiry)
+}
+
+/*
+Usable - Returns true if the stored token can still be used for authentication, either because its access token
+has not expired or because it can be refreshed. Returns false if the stored token or its token set is nil, such as
+when a corrupt token file is loaded
+*/
+func (token *StoredToken) Usable() bool {
+	if token == nil || token.Token == nil {
+		return false
+	}
+
+	return !token.Expired() || token.Token.RefreshToken != ""
+}
+
+/*
+TokenStore - An interface for persisting the token set of an HTTPClient, so that a token obtained once can be
+reused across process restarts
+*/
+type TokenStore interface {
+	// Load - Returns the saved token. Returns ErrNoStoredToken if no token has been saved
+	Load() (*StoredToken, error)
+
+	// Save - Persist the token, replacing any previously saved token
+	Save(token *StoredToken) error
+
+	// Clear - Remove the saved token. Clearing an empty store is not an error
+	Clear() error
+}
+
+/*
+MemoryTokenStore - A TokenStore that keeps the token in memory. Tokens saved here do not survive a restart of the
+process, but can be shared between multiple clients
+*/
+type MemoryTokenStore struct {
+	// token - The currently saved token
+	token *StoredToken
+
+	// mutex - Guards the saved token
+	mutex sync.RWMutex
+}
+
+/*
+NewMemoryTokenStore - Create a new, empty MemoryTokenStore
+*/
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{}
+}
+
+/*
+Load - Returns the saved token. Returns ErrNoStoredToken if no token has been saved
+*/
+func (store *MemoryTokenStore) Load() (*StoredToken, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	if store.token == nil {
+		return nil, ErrNoStoredToken
+	}
+
+	return store.token, nil
+}
+
+/*
+Save - Keep the token in memory, replacing any previously saved token
+*/
+func (store *MemoryTokenStore) Save(token *StoredToken) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.token = token
+
+	return nil
+}
+
+/*
+Clear - Remove the saved token
+*/
+func (store *MemoryTokenStore) Clear() error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.token = nil
+
+	return nil
+}
+
+/*
+FileTokenStore - A TokenStore that persists the token as JSON in a file that is only readable by the current user
+*/
+type FileTokenStore struct {
+	// path - The absolute path of the file the token is saved to
+	path string
+
+	// mutex - Serializes reads and writes of the token file
+	mutex sync.Mutex
+}
+
+/*
+NewFileTokenStore - Create a new FileTokenStore that saves the token to the path passed in the parameter. A leading
+~ is expanded to the home directory of the current user. If the path is empty, DefaultTokenPath is used
+*/
+func NewFileTokenStore(path string) (*FileTokenStore, error) {
+	if path == "" {
+		path = DefaultTokenPath
+	}
+
+	path, err := homedir.Expand(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileTokenStore{path: path}, nil
+}
+
+/*
+Path - Returns the path of the file the token is saved to
+*/
+func (store *FileTokenStore) Path() string {
+	return store.path
+}
+
+/*
+Load - Read the saved token from disk. Returns ErrNoStoredToken if the file does not exist
+*/
+func (store *FileTokenStore) Load() (*StoredToken, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	data, err := os.ReadFile(store.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoStoredToken
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var token StoredToken
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, err
+	}
+
+	if token.Token == nil {
+		return nil, ErrNoStoredToken
+	}
+
+	return &token, nil
+}
+
+/*
+Save - Write the token to disk with 0600 permissions. The token is written to a temporary file first and then
+renamed, so that a crash never leaves a partially written token behind
+*/
+func (store *FileTokenStore) Save(token *StoredToken) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(store.path), 0700); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(store.path), ".token-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if err := file.Chmod(0600); err != nil {
+		file.Close()
+		return err
+	}
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), store.path)
+}
+
+/*
+Clear - Remove the token file from disk
+*/
+func (store *FileTokenStore) Clear() error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	err := os.Remove(store.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+/*
+SetTokenStore - Set the store used for persisting the token of the client. If the client already holds a token then
+it is saved to the store, otherwise any token previously saved to the store is loaded. A saved token is only reused
+if its access token has not expired, or if it can be refreshed. Any token set on the client afterward is saved to
+the store
+*/
+func (client *HTTPClient) SetTokenStore(store TokenStore) error {
+	client.tokenMutex.Lock()
+	defer client.tokenMutex.Unlock()
+
+	client.tokenStore = store
+
+	if store == nil {
+		return nil
+	}
+
+	if client.token != nil {
+		return store.Save(&StoredToken{Token: client.token, Expiry: client.tokenExpiry})
+	}
+
+	stored, err := store.Load()
+	if errors.Is(err, ErrNoStoredToken) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if !stored.Usable() { // nothing can be done with this token anymore
+		return store.Clear()
+	}
+
+	client.token = stored.Token
+	client.tokenExpiry = stored.Expiry
+
+	return nil
+}
+
+/*
+SetStoredToken - Sets the authentication token for the current session from a token that was previously persisted,
+keeping its original expiry. Returns ErrNoStoredToken if the token is nil or can no longer be used
+*/
+func (client *HTTPClient) SetStoredToken(stored *StoredToken) error {
+	if !stored.Usable() {
+		return ErrNoStoredToken
+	}
+
+	return client.setToken(stored.Token, stored.Expiry)
+}
+
+/*
+TokenStore - Returns the store used for persisting the token of the client. Returns nil if no store has been set
+*/
+func (client *HTTPClient) TokenStore() TokenStore {
+	client.tokenMutex.RLock()
+	defer client.tokenMutex.RUnlock()
+
+	return client.tokenStore
+}
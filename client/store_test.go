@@ -0,0 +1,167 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"github.com/auth0/go-auth0/authentication/oauth"
+	apiModels "github.com/stevezaluk/mtgjson-models/api"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileTokenStore(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nested", "token.json")
+
+	store, err := NewFileTokenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Load(); !errors.Is(err, ErrNoStoredToken) {
+		t.Fatalf("Load() on an empty store error = %v, want ErrNoStoredToken", err)
+	}
+
+	expiry := time.Now().Add(time.Hour).Truncate(time.Second)
+	if err := store.Save(&StoredToken{Token: &oauth.TokenSet{AccessToken: "access"}, Expiry: expiry}); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("token file mode = %o, want 600", mode)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 {
+		t.Errorf("token directory holds %d files, want only the token file", len(entries))
+	}
+
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.Token.AccessToken != "access" || !loaded.Expiry.Equal(expiry) {
+		t.Errorf("Load() = %+v, want the saved token", loaded)
+	}
+
+	for range 2 { // clearing an empty store is not an error
+		if err := store.Clear(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := store.Load(); !errors.Is(err, ErrNoStoredToken) {
+		t.Errorf("Load() after Clear() error = %v, want ErrNoStoredToken", err)
+	}
+}
+
+/*
+failingStore - A TokenStore whose Save always fails
+*/
+type failingStore struct {
+	MemoryTokenStore
+}
+
+/*
+Save - Returns errSaveFailed
+*/
+func (store *failingStore) Save(*StoredToken) error {
+	return errSaveFailed
+}
+
+/*
+errSaveFailed - Returned by failingStore.Save
+*/
+var errSaveFailed = errors.New("save failed")
+
+func TestRefreshSurvivesStoreError(t *testing.T) {
+	server, hits := newTokenServer(t)
+
+	var storeErr error
+	client, err := New(WithTokenStore(&failingStore{}), WithStoreErrorHandler(func(err error) {
+		storeErr = err
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = client.SetBearerToken(&oauth.TokenSet{AccessToken: "old", RefreshToken: "refresh"})
+	if !errors.Is(err, errSaveFailed) {
+		t.Fatalf("SetBearerToken() error = %v, want the store error", err)
+	}
+
+	client.SetTokenRefresher(func(context.Context, string) (*oauth.TokenSet, error) {
+		return &oauth.TokenSet{AccessToken: "new"}, nil
+	})
+
+	_, err = Execute[apiModels.APIResponse](client.BuildRequest(context.Background()), http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("Execute() error = %v, want the request to be retried with the refreshed token", err)
+	}
+
+	if hits.Load() != 2 {
+		t.Errorf("requests = %d, want 2", hits.Load())
+	}
+
+	if !errors.Is(storeErr, errSaveFailed) {
+		t.Errorf("store error handler received %v, want the store error", storeErr)
+	}
+}
+
+func TestStoredTokenUsable(t *testing.T) {
+	tests := []struct {
+		name   string
+		stored *StoredToken
+		want   bool
+	}{
+		{name: "nil stored token", stored: nil, want: false},
+		{name: "nil token set", stored: &StoredToken{Expiry: time.Now().Add(time.Hour)}, want: false},
+		{name: "not expired", stored: &StoredToken{Token: &oauth.TokenSet{AccessToken: "access"}, Expiry: time.Now().Add(time.Hour)}, want: true},
+		{name: "no expiry", stored: &StoredToken{Token: &oauth.TokenSet{AccessToken: "access"}}, want: true},
+		{name: "expired", stored: &StoredToken{Token: &oauth.TokenSet{AccessToken: "access"}, Expiry: time.Now().Add(-time.Hour)}, want: false},
+		{
+			name:   "expired with a refresh token",
+			stored: &StoredToken{Token: &oauth.TokenSet{AccessToken: "access", RefreshToken: "refresh"}, Expiry: time.Now().Add(-time.Hour)},
+			want:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.stored.Usable(); got != test.want {
+				t.Errorf("Usable() = %t, want %t", got, test.want)
+			}
+		})
+	}
+
+	store := NewMemoryTokenStore()
+	if err := store.Save(&StoredToken{}); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.SetTokenStore(store); err != nil {
+		t.Fatalf("SetTokenStore() with a stored token without a token set error = %v", err)
+	}
+
+	if client.BearerToken() != nil {
+		t.Errorf("BearerToken() = %v, want the unusable stored token to be ignored", client.BearerToken())
+	}
+}
@@ -0,0 +1,122 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-resty/resty/v2"
+	apiModels "github.com/stevezaluk/mtgjson-models/api"
+	"io"
+	"iter"
+)
+
+/*
+ErrMalformedStream - Returned when the body of a streamed response is not a JSON array of the expected items
+*/
+var ErrMalformedStream = errors.New("client: streamed response body is not a JSON array")
+
+/*
+errStopIteration - Returned from the callback of Seq when the consumer of the iterator stops early. It is never
+returned to the caller
+*/
+var errStopIteration = errors.New("client: iteration stopped")
+
+/*
+Stream - Executes a request built with HTTPClient.BuildRequest whose response body is a JSON array, decoding it one
+element at a time and passing each element to fn as soon as it is decoded. Unlike Execute, the array is never held in
+memory as a whole, so the memory used is bounded by the size of a single element. Non-2xx responses are converted into
+an APIError in the same way as Execute. If fn returns an error then decoding stops, the response body is closed and
+the error is returned as is
+*/
+func Stream[T any](request *resty.Request, method string, url string, errorMap ErrorMap, fn func(item *T) error) error {
+	resp, err := send(request.SetDoNotParseResponse(true), method, url)
+	if err != nil {
+		return err
+	}
+	defer closeBody(resp)
+
+	if !resp.IsSuccess() {
+		response, _ := resp.Error().(*apiModels.APIResponse)
+		if response != nil {
+			_ = json.NewDecoder(resp.RawBody()).Decode(response) // an undecodable body leaves the response empty, as with Execute
+		}
+
+		return NewAPIError(resp, errorMap.Resolve(resp.StatusCode(), response))
+	}
+
+	err = decodeArray(resp.RawBody(), fn)
+	if errors.Is(err, ErrMalformedStream) && request.Context().Err() != nil { // reading the body fails once the context is cancelled
+		return request.Context().Err()
+	}
+
+	return err
+}
+
+/*
+Seq - Adapts a function that streams items into a callback, such as a call to Stream, into an iterator. Each item is
+yielded with a nil error, and an error returned from stream is yielded once with a nil item. Breaking out of the loop
+stops the stream
+*/
+func Seq[T any](stream func(fn func(item *T) error) error) iter.Seq2[*T, error] {
+	return func(yield func(*T, error) bool) {
+		err := stream(func(item *T) error {
+			if !yield(item, nil) {
+				return errStopIteration
+			}
+
+			return nil
+		})
+
+		if err != nil && !errors.Is(err, errStopIteration) {
+			yield(nil, err)
+		}
+	}
+}
+
+/*
+decodeArray - Decodes the JSON array read from the reader one element at a time, passing each element to fn. A JSON
+null is treated as an empty array
+*/
+func decodeArray[T any](reader io.Reader, fn func(item *T) error) error {
+	decoder := json.NewDecoder(reader)
+
+	token, err := decoder.Token()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrMalformedStream, err)
+	}
+
+	if token == nil {
+		return nil
+	}
+
+	if token != json.Delim('[') {
+		return fmt.Errorf("%w: unexpected token %v", ErrMalformedStream, token)
+	}
+
+	for decoder.More() {
+		item := new(T)
+		if err := decoder.Decode(item); err != nil {
+			return fmt.Errorf("%w: %w", ErrMalformedStream, err)
+		}
+
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return fmt.Errorf("%w: %w", ErrMalformedStream, err)
+	}
+
+	return nil
+}
+
+/*
+closeBody - Closes the body of a response that was not read by resty. This is safe to call on any response, including
+a nil one
+*/
+func closeBody(resp *resty.Response) {
+	if resp != nil && resp.RawBody() != nil {
+		_ = resp.RawBody().Close()
+	}
+}
@@ -0,0 +1,239 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"github.com/auth0/go-auth0/authentication/oauth"
+	"github.com/go-resty/resty/v2"
+	"time"
+)
+
+/*
+refreshWindow - How long before the expiry of the access token the client proactively refreshes it
+*/
+const refreshWindow = 30 * time.Second
+
+/*
+ErrNoRefreshToken - Returned when the access token needs to be refreshed but the token set has no refresh token,
+or no RefreshFunc has been set on the client
+*/
+var ErrNoRefreshToken = errors.New("client: unable to refresh the access token, no refresh token or refresher is available")
+
+/*
+RefreshFunc - Exchanges a refresh token for a new oauth.TokenSet. The api package wires this to AuthAPI.RefreshToken
+*/
+type RefreshFunc func(ctx context.Context, refreshToken string) (*oauth.TokenSet, error)
+
+/*
+StoreErrorFunc - Receives an error returned by the TokenStore while saving a token that the client refreshed on its
+own. The refreshed token is still used, so the error only means that it will not survive a restart of the process
+*/
+type StoreErrorFunc func(err error)
+
+/*
+skipAuthKey - Context key used to mark requests that must not trigger a token refresh, such as the refresh request itself
+*/
+type skipAuthKey struct{}
+
+/*
+SetBearerToken - Sets the authentication token for the current session. The expiry of the access token is
+calculated from the ExpiresIn field of the token set, and is used for refreshing the token before it expires.
+If a TokenStore has been set, the token is saved to it and any error from the store is returned. The token is used
+by the client even if it could not be saved
+*/
+func (client *HTTPClient) SetBearerToken(token *oauth.TokenSet) error {
+	if token == nil {
+		return nil
+	}
+
+	var expiry time.Time
+	if token.ExpiresIn > 0 {
+		expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+
+	return client.setToken(token, expiry)
+}
+
+/*
+ClearBearerToken - Remove the authentication token from the current session and from the TokenStore, if one has been set
+*/
+func (client *HTTPClient) ClearBearerToken() error {
+	client.tokenMutex.Lock()
+	defer client.tokenMutex.Unlock()
+
+	client.token = nil
+	client.tokenExpiry = time.Time{}
+
+	if client.tokenStore == nil {
+		return nil
+	}
+
+	return client.tokenStore.Clear()
+}
+
+/*
+setToken - Swap the token and its expiry, guarded by the token mutex, and save it to the TokenStore. The token is
+swapped even if saving it fails
+*/
+func (client *HTTPClient) setToken(token *oauth.TokenSet, expiry time.Time) error {
+	client.tokenMutex.Lock()
+	defer client.tokenMutex.Unlock()
+
+	client.token = token
+	client.tokenExpiry = expiry
+
+	if client.tokenStore == nil {
+		return nil
+	}
+
+	return client.tokenStore.Save(&StoredToken{Token: token, Expiry: expiry})
+}
+
+/*
+BearerToken - Returns the token set currently used for authentication. Returns nil if no token has been set
+*/
+func (client *HTTPClient) BearerToken() *oauth.TokenSet {
+	client.tokenMutex.RLock()
+	defer client.tokenMutex.RUnlock()
+
+	return client.token
+}
+
+/*
+TokenExpiry - Returns the time at which the current access token expires. A zero time is returned if no token has
+been set, or if the token set did not provide an expiry
+*/
+func (client *HTTPClient) TokenExpiry() time.Time {
+	client.tokenMutex.RLock()
+	defer client.tokenMutex.RUnlock()
+
+	return client.tokenExpiry
+}
+
+/*
+SetTokenRefresher - Set the function used for exchanging the refresh token for a new token set
+*/
+func (client *HTTPClient) SetTokenRefresher(refresher RefreshFunc) {
+	client.tokenMutex.Lock()
+	defer client.tokenMutex.Unlock()
+
+	client.refresher = refresher
+}
+
+/*
+SetStoreErrorHandler - Set the function that receives errors from the TokenStore while saving a refreshed token.
+Without one, these errors are discarded, as a refresh that succeeded is not failed because its token could not be saved
+*/
+func (client *HTTPClient) SetStoreErrorHandler(handler StoreErrorFunc) {
+	client.tokenMutex.Lock()
+	defer client.tokenMutex.Unlock()
+
+	client.storeErrorHandler = handler
+}
+
+/*
+expiresSoon - Returns true if the access token expires within the refresh window. Must be called with the token mutex held
+*/
+func (client *HTTPClient) expiresSoon() bool {
+	return !client.tokenExpiry.IsZero() && time.Until(client.tokenExpiry) < refreshWindow
+}
+
+/*
+accessToken - Returns the access token that should be attached to a request, proactively refreshing it if
+it is about to expire. If the refresh fails, the current token is returned so that the request can still be made
+*/
+func (client *HTTPClient) accessToken(ctx context.Context) string {
+	client.tokenMutex.RLock()
+	token := client.token
+	expiresSoon := client.expiresSoon()
+	client.tokenMutex.RUnlock()
+
+	if token == nil {
+		return ""
+	}
+
+	if expiresSoon {
+		_ = client.refreshToken(ctx, token.AccessToken) // on failure the current token is still attempted
+
+		if token = client.BearerToken(); token == nil {
+			return ""
+		}
+	}
+
+	return token.AccessToken
+}
+
+/*
+refreshToken - Exchange the refresh token for a new token set. staleAccessToken is the access token that the caller
+determined to be expired, if another goroutine has already replaced it then no refresh is made. Refreshes are
+serialized so that goroutines sharing the client only refresh once. An error saving the refreshed token to the
+TokenStore does not fail the refresh, it is passed to the StoreErrorFunc of the client instead
+*/
+func (client *HTTPClient) refreshToken(ctx context.Context, staleAccessToken string) error {
+	client.refreshMutex.Lock()
+	defer client.refreshMutex.Unlock()
+
+	client.tokenMutex.RLock()
+	token := client.token
+	refresher := client.refresher
+	client.tokenMutex.RUnlock()
+
+	if token == nil || refresher == nil || token.RefreshToken == "" {
+		return ErrNoRefreshToken
+	}
+
+	if token.AccessToken != staleAccessToken { // another goroutine already refreshed the token
+		return nil
+	}
+
+	refreshed, err := refresher(context.WithValue(ctx, skipAuthKey{}, true), token.RefreshToken)
+	if err != nil {
+		return err
+	}
+
+	if refreshed.RefreshToken == "" { // refresh token rotation is optional, keep the previous one
+		refreshed.RefreshToken = token.RefreshToken
+	}
+
+	if err := client.SetBearerToken(refreshed); err != nil {
+		client.tokenMutex.RLock()
+		handler := client.storeErrorHandler
+		client.tokenMutex.RUnlock()
+
+		if handler != nil {
+			handler(err)
+		}
+	}
+
+	return nil
+}
+
+/*
+authMiddleware - Resty middleware that attaches the current access token to each request attempt, refreshing
+the token beforehand if it is about to expire
+*/
+func (client *HTTPClient) authMiddleware(_ *resty.Client, request *resty.Request) error {
+	if skip, _ := request.Context().Value(skipAuthKey{}).(bool); skip {
+		return nil
+	}
+
+	if accessToken := client.accessToken(request.Context()); accessToken != "" {
+		request.SetAuthToken(accessToken)
+	}
+
+	return nil
+}
+
+/*
+canRefresh - Returns true if a request that failed with a 401 should be retried after refreshing the access token
+*/
+func (client *HTTPClient) canRefresh(request *resty.Request) bool {
+	if skip, _ := request.Context().Value(skipAuthKey{}).(bool); skip {
+		return false
+	}
+
+	client.tokenMutex.RLock()
+	defer client.tokenMutex.RUnlock()
+
+	return client.token != nil && client.token.RefreshToken != "" && client.refresher != nil
+}
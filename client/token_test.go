@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"github.com/auth0/go-auth0/authentication/oauth"
+	apiModels "github.com/stevezaluk/mtgjson-models/api"
+	sdkErrors "github.com/stevezaluk/mtgjson-models/errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+/*
+newTokenServer - Start a server that accepts only the access token "new", and count the requests made to it
+*/
+func newTokenServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	var hits atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		hits.Add(1)
+
+		if request.Header.Get("Authorization") != "Bearer new" {
+			writer.WriteHeader(http.StatusUnauthorized)
+			_, _ = writer.Write([]byte(`{"message":"unauthorized"}`))
+			return
+		}
+
+		_, _ = writer.Write([]byte(`{"message":"ok"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &hits
+}
+
+func TestTokenRefresh(t *testing.T) {
+	tests := []struct {
+		name      string
+		expiresIn int64
+		refresher bool
+		wantErr   error
+		wantHits  int32
+	}{
+		{name: "reactive refresh after a 401", refresher: true, wantHits: 2},
+		{name: "proactive refresh before expiry", expiresIn: 1, refresher: true, wantHits: 1},
+		{name: "no refresher", wantErr: sdkErrors.ErrTokenInvalid, wantHits: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, hits := newTokenServer(t)
+
+			client, err := New()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = client.SetBearerToken(&oauth.TokenSet{AccessToken: "old", RefreshToken: "refresh", ExpiresIn: test.expiresIn})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var refreshes atomic.Int32
+			if test.refresher {
+				client.SetTokenRefresher(func(context.Context, string) (*oauth.TokenSet, error) {
+					refreshes.Add(1)
+					return &oauth.TokenSet{AccessToken: "new"}, nil
+				})
+			}
+
+			_, err = Execute[apiModels.APIResponse](client.BuildRequest(context.Background()), http.MethodGet, server.URL, nil)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("Execute() error = %v, want %v", err, test.wantErr)
+			}
+
+			if got := hits.Load(); got != test.wantHits {
+				t.Errorf("requests = %d, want %d", got, test.wantHits)
+			}
+
+			if test.refresher && refreshes.Load() != 1 {
+				t.Errorf("refreshes = %d, want 1", refreshes.Load())
+			}
+
+			if test.refresher && client.BearerToken().RefreshToken != "refresh" {
+				t.Errorf("refresh token = %q, want the previous refresh token to be kept", client.BearerToken().RefreshToken)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package client
+
+import (
+	"encoding/json"
+	"github.com/go-resty/resty/v2"
+	"reflect"
+)
+
+/*
+IfMatchHeader - The header that carries the modified date of the copy of a model that an update was based on. The API
+rejects the update with a 412 if the stored model has been modified since
+*/
+const IfMatchHeader = "If-Match"
+
+/*
+MergePatchContentType - The content type of a JSON merge patch (RFC 7386), as sent by the Patch methods of each
+namespace
+*/
+const MergePatchContentType = "application/merge-patch+json"
+
+/*
+SetIfMatch - Set the If-Match header of the request to the modified date passed in the parameter, so that the update is
+rejected with ErrConflict if the model was modified by someone else after it was read. An empty modified date sends the
+update unconditionally
+*/
+func SetIfMatch(request *resty.Request, modifiedDate string) *resty.Request {
+	if modifiedDate != "" {
+		request.SetHeader(IfMatchHeader, modifiedDate)
+	}
+
+	return request
+}
+
+/*
+MergePatch - Returns the JSON merge patch (RFC 7386) that turns the JSON encoding of original into the JSON encoding of
+updated. Only the fields that differ are included, and fields that are no longer present in updated are set to null
+so that the API removes them
+*/
+func MergePatch(original any, updated any) (map[string]any, error) {
+	originalFields, err := jsonObject(original)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedFields, err := jsonObject(updated)
+	if err != nil {
+		return nil, err
+	}
+
+	return diffObjects(originalFields, updatedFields), nil
+}
+
+/*
+jsonObject - Returns the JSON encoding of the value passed in the parameter decoded as a generic JSON object
+*/
+func jsonObject(value any) (map[string]any, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	object := make(map[string]any)
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+
+	return object, nil
+}
+
+/*
+diffObjects - Returns the merge patch between two decoded JSON objects. Nested objects are diffed recursively, while
+any other value, including arrays, is replaced as a whole
+*/
+func diffObjects(original map[string]any, updated map[string]any) map[string]any {
+	patch := make(map[string]any)
+
+	for key, value := range updated {
+		previous, ok := original[key]
+		if ok && reflect.DeepEqual(previous, value) {
+			continue
+		}
+
+		previousObject, previousIsObject := previous.(map[string]any)
+		valueObject, valueIsObject := value.(map[string]any)
+
+		if previousIsObject && valueIsObject {
+			patch[key] = diffObjects(previousObject, valueObject)
+			continue
+		}
+
+		patch[key] = value
+	}
+
+	for key := range original {
+		if _, ok := updated[key]; !ok {
+			patch[key] = nil
+		}
+	}
+
+	return patch
+}
+
+/*
+Overlay - Returns a copy of existing with each field that is set in the JSON encoding of desired written over it.
+Nested objects are overlaid recursively, while any other value, including arrays, is replaced as a whole. Fields that
+desired leaves unset, including fields holding their zero value when they are encoded with omitempty, keep the value
+they have in existing, so a merge patch from existing to the result never removes a field
+*/
+func Overlay[T any](existing *T, desired *T) (*T, error) {
+	existingFields, err := jsonObject(existing)
+	if err != nil {
+		return nil, err
+	}
+
+	desiredFields, err := jsonObject(desired)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(overlayObjects(existingFields, desiredFields))
+	if err != nil {
+		return nil, err
+	}
+
+	result := new(T)
+	if err := json.Unmarshal(data, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+/*
+overlayObjects - Writes the fields of overlay over the decoded JSON object base and returns it
+*/
+func overlayObjects(base map[string]any, overlay map[string]any) map[string]any {
+	for key, value := range overlay {
+		baseObject, baseIsObject := base[key].(map[string]any)
+		valueObject, valueIsObject := value.(map[string]any)
+
+		if baseIsObject && valueIsObject {
+			base[key] = overlayObjects(baseObject, valueObject)
+			continue
+		}
+
+		base[key] = value
+	}
+
+	return base
+}
+
+/*
+UpsertAction - Reports what an Upsert method of a namespace did to reach the desired state of a model
+*/
+type UpsertAction int
+
+const (
+	// UpsertUnchanged - The model already existed and matched the desired one, so no request was made to change it
+	UpsertUnchanged UpsertAction = iota
+
+	// UpsertCreated - The model did not exist and was created
+	UpsertCreated
+
+	// UpsertUpdated - The model existed with different fields and was updated
+	UpsertUpdated
+)
+
+/*
+String - Returns a lowercase name for the action, for use in logs and summaries
+*/
+func (action UpsertAction) String() string {
+	switch action {
+	case UpsertUnchanged:
+		return "unchanged"
+	case UpsertCreated:
+		return "created"
+	case UpsertUpdated:
+		return "updated"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,153 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	type legalities struct {
+		Modern   string `json:"modern,omitempty"`
+		Standard string `json:"standard,omitempty"`
+	}
+
+	type model struct {
+		Name       string      `json:"name,omitempty"`
+		Colors     []string    `json:"colors,omitempty"`
+		Text       string      `json:"text,omitempty"`
+		Legalities *legalities `json:"legalities,omitempty"`
+	}
+
+	tests := []struct {
+		name     string
+		original model
+		updated  model
+		want     map[string]any
+	}{
+		{
+			name:     "unchanged",
+			original: model{Name: "Lightning Bolt", Colors: []string{"R"}},
+			updated:  model{Name: "Lightning Bolt", Colors: []string{"R"}},
+			want:     map[string]any{},
+		},
+		{
+			name:     "changed field",
+			original: model{Name: "Lightning Bolt", Text: "Deal 3 damage."},
+			updated:  model{Name: "Lightning Bolt", Text: "Lightning Bolt deals 3 damage to any target."},
+			want:     map[string]any{"text": "Lightning Bolt deals 3 damage to any target."},
+		},
+		{
+			name:     "added field",
+			original: model{Name: "Lightning Bolt"},
+			updated:  model{Name: "Lightning Bolt", Colors: []string{"R"}},
+			want:     map[string]any{"colors": []any{"R"}},
+		},
+		{
+			name:     "removed field",
+			original: model{Name: "Lightning Bolt", Text: "Deal 3 damage."},
+			updated:  model{Name: "Lightning Bolt"},
+			want:     map[string]any{"text": nil},
+		},
+		{
+			name:     "arrays are replaced",
+			original: model{Colors: []string{"R", "G"}},
+			updated:  model{Colors: []string{"R"}},
+			want:     map[string]any{"colors": []any{"R"}},
+		},
+		{
+			name:     "nested objects are diffed",
+			original: model{Legalities: &legalities{Modern: "Legal", Standard: "Legal"}},
+			updated:  model{Legalities: &legalities{Modern: "Legal", Standard: "Banned"}},
+			want:     map[string]any{"legalities": map[string]any{"standard": "Banned"}},
+		},
+		{
+			name:     "removed nested object",
+			original: model{Name: "Lightning Bolt", Legalities: &legalities{Modern: "Legal"}},
+			updated:  model{Name: "Lightning Bolt"},
+			want:     map[string]any{"legalities": nil},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := MergePatch(test.original, test.updated)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("MergePatch() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestOverlay(t *testing.T) {
+	type legalities struct {
+		Modern   string `json:"modern,omitempty"`
+		Standard string `json:"standard,omitempty"`
+	}
+
+	type model struct {
+		Name       string      `json:"name,omitempty"`
+		Colors     []string    `json:"colors,omitempty"`
+		Text       string      `json:"text,omitempty"`
+		Legalities *legalities `json:"legalities,omitempty"`
+	}
+
+	tests := []struct {
+		name     string
+		existing model
+		desired  model
+		want     model
+	}{
+		{
+			name:     "unset fields are kept",
+			existing: model{Name: "Lightning Bolt", Text: "Deal 3 damage.", Colors: []string{"R"}},
+			desired:  model{Name: "Lightning Bolt"},
+			want:     model{Name: "Lightning Bolt", Text: "Deal 3 damage.", Colors: []string{"R"}},
+		},
+		{
+			name:     "set fields are replaced",
+			existing: model{Name: "Lightning Bolt", Text: "Deal 3 damage."},
+			desired:  model{Text: "Lightning Bolt deals 3 damage to any target."},
+			want:     model{Name: "Lightning Bolt", Text: "Lightning Bolt deals 3 damage to any target."},
+		},
+		{
+			name:     "arrays are replaced",
+			existing: model{Colors: []string{"R", "G"}},
+			desired:  model{Colors: []string{"U"}},
+			want:     model{Colors: []string{"U"}},
+		},
+		{
+			name:     "nested objects are overlaid",
+			existing: model{Legalities: &legalities{Modern: "Legal", Standard: "Legal"}},
+			desired:  model{Legalities: &legalities{Standard: "Banned"}},
+			want:     model{Legalities: &legalities{Modern: "Legal", Standard: "Banned"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Overlay(&test.existing, &test.desired)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(*got, test.want) {
+				t.Errorf("Overlay() = %+v, want %+v", *got, test.want)
+			}
+
+			patch, err := MergePatch(test.existing, *got)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for key, value := range patch {
+				if value == nil {
+					t.Errorf("the merge patch to the overlaid model removes %q", key)
+				}
+			}
+		})
+	}
+}
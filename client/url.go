@@ -0,0 +1,53 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+/*
+ErrInvalidBaseURL - Returned when the base URL of the API is malformed or is not an absolute HTTP(S) URL
+*/
+var ErrInvalidBaseURL = errors.New("client: invalid base URL")
+
+/*
+ParseBaseURL - Parse and validate the base URL of an MTGJSON API server. The URL must be an absolute http or https
+URL with a host, and may contain a path prefix when the server is mounted behind a reverse proxy (for example
+https://example.com/mtgjson/v1). Query strings, fragments and user info are rejected
+*/
+func ParseBaseURL(rawUrl string) (*url.URL, error) {
+	parsed, err := url.Parse(rawUrl)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBaseURL, err)
+	}
+
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, fmt.Errorf("%w: scheme must be http or https, got %q", ErrInvalidBaseURL, parsed.Scheme)
+	}
+
+	if parsed.Hostname() == "" {
+		return nil, fmt.Errorf("%w: %q has no host", ErrInvalidBaseURL, rawUrl)
+	}
+
+	if parsed.RawQuery != "" || parsed.Fragment != "" || parsed.User != nil {
+		return nil, fmt.Errorf("%w: %q must not contain a query, fragment or user info", ErrInvalidBaseURL, rawUrl)
+	}
+
+	return parsed, nil
+}
+
+/*
+JoinURL - Validate the base URL passed in the parameter with ParseBaseURL and join the path elements to it. Slashes
+between the base URL and the elements are normalized, and the returned URL never ends with a slash so that
+sub-paths can be appended to it
+*/
+func JoinURL(baseUrl string, elem ...string) (string, error) {
+	parsed, err := ParseBaseURL(baseUrl)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(parsed.JoinPath(elem...).String(), "/"), nil
+}
@@ -1,10 +1,13 @@
 package deck
 
 import (
+	"context"
+	"errors"
 	apiModels "github.com/stevezaluk/mtgjson-models/api"
 	deckModel "github.com/stevezaluk/mtgjson-models/deck"
 	sdkErrors "github.com/stevezaluk/mtgjson-models/errors"
 	"github.com/stevezaluk/mtgjson-sdk-client/client"
+	"google.golang.org/protobuf/proto"
 	"net/http"
 )
 
@@ -20,15 +23,19 @@ type DeckAPI struct {
 }
 
 /*
-New Create a new instance of the DeckAPI struct
+New Create a new instance of the DeckAPI struct. The base URL must be an absolute http(s) URL and may
+contain a path prefix, client.ErrInvalidBaseURL is returned if it is malformed
 */
-func New(baseUrl string, client *client.HTTPClient) *DeckAPI {
-	// add check to validate baseUrl here
+func New(baseUrl string, httpClient *client.HTTPClient) (*DeckAPI, error) {
+	endpoint, err := client.JoinURL(baseUrl, "deck")
+	if err != nil {
+		return nil, err
+	}
 
 	return &DeckAPI{
-		baseUrl: baseUrl + "/deck",
-		client:  client,
-	}
+		baseUrl: endpoint,
+		client:  httpClient,
+	}, nil
 }
 
 /*
@@ -45,39 +52,37 @@ func (api *DeckAPI) Client() *client.HTTPClient {
 	return api.client
 }
 
+/*
+getDeckErrors - Maps the status codes returned from GET /deck to sentinel errors
+*/
+var getDeckErrors = client.ErrorMap{
+	http.StatusNotFound:   {Default: sdkErrors.ErrNoDeck},
+	http.StatusBadRequest: {Default: sdkErrors.ErrDeckMissingId}, // this should never get returned
+}
+
 /*
 GetDeck Fetch a deck from the MongoDB database using the code passed in the parameter. Owner
 is the email address of the user that you want to assign to the deck. If the string is empty
 then it does not filter by user. Returns ErrNoDeck if the deck does not exist or cannot be located
 */
-func (api *DeckAPI) GetDeck(code string, owner string) (*deckModel.Deck, error) {
-	request := api.client.BuildRequest(&deckModel.Deck{}).
+func (api *DeckAPI) GetDeck(ctx context.Context, code string, owner string) (*deckModel.Deck, error) {
+	request := api.client.BuildRequest(ctx).
 		SetQueryParams(map[string]string{"deckCode": code, "owner": owner})
 
-	resp, err := request.Get(api.baseUrl)
-	if err != nil {
-		return nil, err
-	}
-
-	if resp.Error() != nil {
-		if resp.StatusCode() == http.StatusUnauthorized {
-			return nil, sdkErrors.ErrTokenInvalid
-		}
-
-		if resp.StatusCode() == http.StatusForbidden {
-			return nil, sdkErrors.ErrInvalidPermissions
-		}
-
-		if resp.StatusCode() == http.StatusNotFound {
-			return nil, sdkErrors.ErrNoDeck
-		}
-
-		if resp.StatusCode() == http.StatusBadRequest { // this should never get returned
-			return nil, sdkErrors.ErrDeckMissingId
-		}
-	}
+	return client.Execute[deckModel.Deck](request, http.MethodGet, api.baseUrl, getDeckErrors)
+}
 
-	return resp.Result().(*deckModel.Deck), nil
+/*
+newDeckErrors - Maps the status codes returned from POST /deck to sentinel errors
+*/
+var newDeckErrors = client.ErrorMap{
+	http.StatusConflict: {Default: sdkErrors.ErrDeckAlreadyExists},
+	http.StatusBadRequest: {Match: []error{
+		sdkErrors.ErrMetaApiMustBeNull,
+		sdkErrors.ErrDeckMissingContentIds,
+		sdkErrors.ErrDeckMissingId,
+		sdkErrors.ErrInvalidCards,
+	}},
 }
 
 /*
@@ -86,224 +91,234 @@ valid name and deck code, additionally the deck cannot already exist under the s
 the email address of the owner you want to assign the deck to. If the string is empty, it will be assigned
 to the system user
 */
-func (api *DeckAPI) NewDeck(deck *deckModel.Deck, owner string) (*apiModels.APIResponse, error) {
-	request := api.client.BuildRequest(&apiModels.APIResponse{}).
+func (api *DeckAPI) NewDeck(ctx context.Context, deck *deckModel.Deck, owner string) (*apiModels.APIResponse, error) {
+	request := api.client.BuildRequest(ctx).
 		SetQueryParam("owner", owner).
 		SetBody(deck)
 
-	resp, err := request.Post(api.baseUrl)
+	return client.Execute[apiModels.APIResponse](request, http.MethodPost, api.baseUrl, newDeckErrors)
+}
+
+/*
+updateDeckErrors - Maps the status codes returned from PUT and PATCH /deck to sentinel errors
+*/
+var updateDeckErrors = client.ErrorMap{
+	http.StatusNotFound: {Default: sdkErrors.ErrNoDeck},
+	http.StatusBadRequest: {Match: []error{
+		sdkErrors.ErrMetaApiMustBeNull,
+		sdkErrors.ErrDeckMissingContentIds,
+		sdkErrors.ErrDeckMissingId,
+		sdkErrors.ErrInvalidCards,
+	}},
+	http.StatusInternalServerError: {Default: sdkErrors.ErrDeckUpdateFailed},
+}
+
+/*
+UpdateDeck Replace the deck stored under the code of the deck model passed in the parameter, keeping
+its owner. The API metadata of the model is not sent, as the API maintains it, but its modified date is
+used to detect conflicting changes: if the stored deck was modified after the model was fetched then
+client.ErrConflict is returned and nothing is changed. A model without metadata replaces the deck
+unconditionally. Returns ErrDeckMissingId if the model is nil. PUT /deck, the If-Match header and the 412
+mapped to client.ErrConflict require a server that supports them, which the upstream MTGJSON API does
+not provide yet
+*/
+func (api *DeckAPI) UpdateDeck(ctx context.Context, deck *deckModel.Deck, owner string) (*apiModels.APIResponse, error) {
+	body, err := withoutMeta(deck)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.Error() != nil {
-		errorResponse := resp.Error().(*apiModels.APIResponse)
-		if resp.StatusCode() == http.StatusUnauthorized {
-			return errorResponse, sdkErrors.ErrTokenInvalid
-		}
-
-		if resp.StatusCode() == http.StatusForbidden {
-			return errorResponse, sdkErrors.ErrInvalidPermissions
-		}
-
-		if resp.StatusCode() == http.StatusConflict {
-			return errorResponse, sdkErrors.ErrDeckAlreadyExists
-		}
+	request := client.SetIfMatch(api.client.BuildRequest(ctx), deck.GetMtgjsonApiMeta().GetModifiedDate()).
+		SetQueryParams(map[string]string{"deckCode": deck.GetCode(), "owner": owner}).
+		SetBody(body)
 
-		if resp.StatusCode() == http.StatusBadRequest {
-			if errorResponse.Err == sdkErrors.ErrMetaApiMustBeNull.Error() {
-				return errorResponse, sdkErrors.ErrMetaApiMustBeNull
-			}
-
-			if errorResponse.Err == sdkErrors.ErrDeckMissingContentIds.Error() {
-				return errorResponse, sdkErrors.ErrDeckMissingContentIds
-			}
-
-			if errorResponse.Err == sdkErrors.ErrDeckMissingId.Error() {
-				return errorResponse, sdkErrors.ErrDeckMissingId
-			}
-
-			if errorResponse.Err == sdkErrors.ErrInvalidCards.Error() {
-				return errorResponse, sdkErrors.ErrInvalidCards
-			}
-		}
-	}
-
-	return resp.Result().(*apiModels.APIResponse), nil
+	return client.Execute[apiModels.APIResponse](request, http.MethodPut, api.baseUrl, updateDeckErrors)
 }
 
 /*
-DeleteDeck Remove a deck from the MongoDB database using the code passed in the
-parameter. Returns ErrNoDeck if the deck does not exist. Returns
-ErrDeckDeleteFailed if the deleted count does not equal 1
+PatchDeck Update only the fields that differ between the original deck model, as it was fetched, and
+the updated one, by sending a JSON merge patch. Fields changed concurrently by someone else are left
+intact, however the modified date of original is still used to detect conflicts in the same way as
+UpdateDeck. Returns ErrDeckMissingId if either model is nil. Like UpdateDeck, this requires PATCH /deck support
+on the server
 */
-func (api *DeckAPI) DeleteDeck(code string, owner string) (*apiModels.APIResponse, error) {
-	request := api.client.BuildRequest(&apiModels.APIResponse{}).
-		SetQueryParams(map[string]string{"deckCode": code, "owner": owner})
+func (api *DeckAPI) PatchDeck(ctx context.Context, original *deckModel.Deck, updated *deckModel.Deck, owner string) (*apiModels.APIResponse, error) {
+	originalBody, err := withoutMeta(original)
+	if err != nil {
+		return nil, err
+	}
 
-	resp, err := request.Delete(api.baseUrl)
+	updatedBody, err := withoutMeta(updated)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.Error() != nil {
-		errorResponse := resp.Error().(*apiModels.APIResponse)
+	patch, err := client.MergePatch(originalBody, updatedBody)
+	if err != nil {
+		return nil, err
+	}
 
-		if resp.StatusCode() == http.StatusUnauthorized {
-			return errorResponse, sdkErrors.ErrTokenInvalid
-		}
+	request := client.SetIfMatch(api.client.BuildRequest(ctx), original.GetMtgjsonApiMeta().GetModifiedDate()).
+		SetQueryParams(map[string]string{"deckCode": original.GetCode(), "owner": owner}).
+		SetHeader("Content-Type", client.MergePatchContentType).
+		SetBody(patch)
 
-		if resp.StatusCode() == http.StatusForbidden {
-			return errorResponse, sdkErrors.ErrInvalidPermissions
-		}
-
-		if resp.StatusCode() == http.StatusNotFound {
-			return errorResponse, sdkErrors.ErrNoDeck
-		}
+	return client.Execute[apiModels.APIResponse](request, http.MethodPatch, api.baseUrl, updateDeckErrors)
+}
 
-		if resp.StatusCode() == http.StatusBadRequest {
-			return errorResponse, sdkErrors.ErrDeckMissingId
-		}
+/*
+UpsertDeck Create the deck passed in the parameter if no deck exists under its code, or update the
+existing deck if any of the fields set in the deck model differ from it. Fields that the model leaves unset,
+or sets to their zero value, keep their stored value, see client.Overlay; use UpdateDeck or PatchDeck to clear
+them. The API metadata of both models is ignored when comparing them, and the update is sent with PatchDeck,
+so client.ErrConflict is returned if the deck is modified concurrently. Returns the action that was taken,
+or ErrDeckMissingId without sending a request if the model is nil or has no code
+*/
+func (api *DeckAPI) UpsertDeck(ctx context.Context, deck *deckModel.Deck, owner string) (client.UpsertAction, error) {
+	desired, err := withoutMeta(deck)
+	if err != nil {
+		return client.UpsertUnchanged, err
+	}
 
-		if resp.StatusCode() == http.StatusInternalServerError {
-			return errorResponse, sdkErrors.ErrDeckDeleteFailed
-		}
+	if deck.GetCode() == "" { // an empty code would fetch the deck index instead of a single deck
+		return client.UpsertUnchanged, sdkErrors.ErrDeckMissingId
 	}
 
-	return resp.Result().(*apiModels.APIResponse), nil
-}
+	existing, err := api.GetDeck(ctx, deck.GetCode(), owner)
+	if errors.Is(err, sdkErrors.ErrNoDeck) {
+		if _, err := api.NewDeck(ctx, desired, owner); err != nil {
+			return client.UpsertUnchanged, err
+		}
 
-/*
-GetDeckContents Update the 'contents' field of the deck passed in the parameter. This accepts a
-pointer and updates this in place to avoid having to copy large amounts of data
-*/
-func (api *DeckAPI) GetDeckContents(code string, owner string) (*deckModel.DeckContents, error) {
-	request := api.client.BuildRequest(&deckModel.DeckContents{}).SetQueryParams(map[string]string{"deckCode": code, "owner": owner})
+		return client.UpsertCreated, nil
+	}
 
-	resp, err := request.Get(api.baseUrl + "/content")
 	if err != nil {
-		return nil, err
+		return client.UpsertUnchanged, err
 	}
 
-	if resp.Error() != nil {
-		if resp.StatusCode() == http.StatusUnauthorized {
-			return nil, sdkErrors.ErrTokenInvalid
-		}
+	current, err := withoutMeta(existing)
+	if err != nil {
+		return client.UpsertUnchanged, err
+	}
 
-		if resp.StatusCode() == http.StatusForbidden {
-			return nil, sdkErrors.ErrInvalidPermissions
-		}
+	merged, err := client.Overlay(current, desired)
+	if err != nil {
+		return client.UpsertUnchanged, err
+	}
 
-		if resp.StatusCode() == http.StatusNotFound {
-			return nil, sdkErrors.ErrNoDeck
-		}
+	if proto.Equal(current, merged) {
+		return client.UpsertUnchanged, nil
+	}
 
-		if resp.StatusCode() == http.StatusBadRequest {
-			return nil, sdkErrors.ErrDeckMissingId
-		}
+	if _, err := api.PatchDeck(ctx, existing, merged, owner); err != nil {
+		return client.UpsertUnchanged, err
 	}
 
-	return resp.Result().(*deckModel.DeckContents), nil
+	return client.UpsertUpdated, nil
 }
 
 /*
-AddCards Update the content ids in the deck model passed with new cards. This should
-probably validate cards in the future
+withoutMeta - Returns a copy of the deck model with its API metadata removed, as the API rejects models that carry it.
+Returns ErrDeckMissingId if the model is nil
 */
-func (api *DeckAPI) AddCards(code string, cards *deckModel.DeckContentIds, owner string) (*apiModels.APIResponse, error) {
-	request := api.client.BuildRequest(&apiModels.APIResponse{}).
-		SetQueryParams(map[string]string{"deckCode": code, "owner": owner}).
-		SetBody(cards)
-
-	resp, err := request.Post(api.baseUrl + "/content")
-	if err != nil {
-		return nil, err
+func withoutMeta(deck *deckModel.Deck) (*deckModel.Deck, error) {
+	if deck == nil {
+		return nil, sdkErrors.ErrDeckMissingId
 	}
 
-	if resp.Error() != nil {
-		errorResponse := resp.Error().(*apiModels.APIResponse)
-
-		if resp.StatusCode() == http.StatusUnauthorized {
-			return errorResponse, sdkErrors.ErrTokenInvalid
-		}
+	deck = proto.Clone(deck).(*deckModel.Deck)
+	deck.MtgjsonApiMeta = nil
 
-		if resp.StatusCode() == http.StatusForbidden {
-			return errorResponse, sdkErrors.ErrInvalidPermissions
-		}
+	return deck, nil
+}
 
-		if resp.StatusCode() == http.StatusNotFound {
-			return errorResponse, sdkErrors.ErrNoDeck
-		}
+/*
+deleteDeckErrors - Maps the status codes returned from DELETE /deck to sentinel errors
+*/
+var deleteDeckErrors = client.ErrorMap{
+	http.StatusNotFound:            {Default: sdkErrors.ErrNoDeck},
+	http.StatusBadRequest:          {Default: sdkErrors.ErrDeckMissingId},
+	http.StatusInternalServerError: {Default: sdkErrors.ErrDeckDeleteFailed},
+}
 
-		if resp.StatusCode() == http.StatusBadRequest {
-			if errorResponse.Err == sdkErrors.ErrInvalidObjectStructure.Error() {
-				return errorResponse, sdkErrors.ErrInvalidObjectStructure
-			}
+/*
+DeleteDeck Remove a deck from the MongoDB database using the code passed in the
+parameter. Returns ErrNoDeck if the deck does not exist. Returns
+ErrDeckDeleteFailed if the deleted count does not equal 1
+*/
+func (api *DeckAPI) DeleteDeck(ctx context.Context, code string, owner string) (*apiModels.APIResponse, error) {
+	request := api.client.BuildRequest(ctx).
+		SetQueryParams(map[string]string{"deckCode": code, "owner": owner})
 
-			if errorResponse.Err == sdkErrors.ErrDeckMissingContentIds.Error() {
-				return errorResponse, sdkErrors.ErrDeckMissingContentIds
-			}
+	return client.Execute[apiModels.APIResponse](request, http.MethodDelete, api.baseUrl, deleteDeckErrors)
+}
 
-			if errorResponse.Err == sdkErrors.ErrInvalidCards.Error() {
-				return errorResponse, sdkErrors.ErrInvalidCards
-			}
+/*
+getDeckContentsErrors - Maps the status codes returned from GET /deck/content to sentinel errors
+*/
+var getDeckContentsErrors = client.ErrorMap{
+	http.StatusNotFound:   {Default: sdkErrors.ErrNoDeck},
+	http.StatusBadRequest: {Match: []error{sdkErrors.ErrInvalidCards}, Default: sdkErrors.ErrDeckMissingId},
+}
 
-			if errorResponse.Err == sdkErrors.ErrDeckNoCards.Error() {
-				return errorResponse, sdkErrors.ErrDeckNoCards
-			}
-		}
+/*
+GetDeckContents Update the 'contents' field of the deck passed in the parameter. This accepts a
+pointer and updates this in place to avoid having to copy large amounts of data
+*/
+func (api *DeckAPI) GetDeckContents(ctx context.Context, code string, owner string) (*deckModel.DeckContents, error) {
+	request := api.client.BuildRequest(ctx).SetQueryParams(map[string]string{"deckCode": code, "owner": owner})
 
-		if resp.StatusCode() == http.StatusInternalServerError {
-			return errorResponse, sdkErrors.ErrDeckUpdateFailed
-		}
-	}
+	return client.Execute[deckModel.DeckContents](request, http.MethodGet, api.baseUrl+"/content", getDeckContentsErrors)
+}
 
-	return resp.Result().(*apiModels.APIResponse), nil
+/*
+addCardsErrors - Maps the status codes returned from POST /deck/content to sentinel errors
+*/
+var addCardsErrors = client.ErrorMap{
+	http.StatusNotFound: {Default: sdkErrors.ErrNoDeck},
+	http.StatusBadRequest: {Match: []error{
+		sdkErrors.ErrInvalidObjectStructure,
+		sdkErrors.ErrDeckMissingContentIds,
+		sdkErrors.ErrInvalidCards,
+		sdkErrors.ErrDeckNoCards,
+	}},
+	http.StatusInternalServerError: {Default: sdkErrors.ErrDeckUpdateFailed},
 }
 
-func (api *DeckAPI) RemoveCards(code string, cards *deckModel.DeckContentIds, owner string) (*apiModels.APIResponse, error) {
-	request := api.client.BuildRequest(&apiModels.APIResponse{}).
+/*
+AddCards Update the content ids in the deck model passed with new cards. This should
+probably validate cards in the future
+*/
+func (api *DeckAPI) AddCards(ctx context.Context, code string, cards *deckModel.DeckContentIds, owner string) (*apiModels.APIResponse, error) {
+	request := api.client.BuildRequest(ctx).
 		SetQueryParams(map[string]string{"deckCode": code, "owner": owner}).
 		SetBody(cards)
 
-	resp, err := request.Delete(api.baseUrl + "/content")
-	if err != nil {
-		return nil, err
-	}
-
-	if resp.Error() != nil {
-		errorResponse := resp.Error().(*apiModels.APIResponse)
-
-		if resp.StatusCode() == http.StatusUnauthorized {
-			return errorResponse, sdkErrors.ErrTokenInvalid
-		}
-
-		if resp.StatusCode() == http.StatusForbidden {
-			return errorResponse, sdkErrors.ErrInvalidPermissions
-		}
-
-		if resp.StatusCode() == http.StatusBadRequest {
-			if errorResponse.Err == sdkErrors.ErrDeckMissingId.Error() {
-				return errorResponse, sdkErrors.ErrDeckMissingId
-			}
-
-			if errorResponse.Err == sdkErrors.ErrInvalidCards.Error() {
-				return errorResponse, sdkErrors.ErrInvalidCards
-			}
-
-			if errorResponse.Err == sdkErrors.ErrDeckNoCards.Error() {
-				return errorResponse, sdkErrors.ErrDeckNoCards
-			}
-		}
+	return client.Execute[apiModels.APIResponse](request, http.MethodPost, api.baseUrl+"/content", addCardsErrors)
+}
 
-		if resp.StatusCode() == http.StatusNotFound {
-			return errorResponse, sdkErrors.ErrNoDeck
-		}
+/*
+removeCardsErrors - Maps the status codes returned from DELETE /deck/content to sentinel errors
+*/
+var removeCardsErrors = client.ErrorMap{
+	http.StatusNotFound: {Default: sdkErrors.ErrNoDeck},
+	http.StatusBadRequest: {Match: []error{
+		sdkErrors.ErrDeckMissingId,
+		sdkErrors.ErrInvalidCards,
+		sdkErrors.ErrDeckNoCards,
+	}},
+	http.StatusInternalServerError: {Default: sdkErrors.ErrDeckUpdateFailed},
+}
 
-		if resp.StatusCode() == http.StatusInternalServerError {
-			return errorResponse, sdkErrors.ErrDeckUpdateFailed
-		}
-	}
+/*
+RemoveCards Remove the content ids passed in the parameter from the deck. Returns ErrNoDeck if the
+deck does not exist, and ErrDeckUpdateFailed if the deck could not be updated
+*/
+func (api *DeckAPI) RemoveCards(ctx context.Context, code string, cards *deckModel.DeckContentIds, owner string) (*apiModels.APIResponse, error) {
+	request := api.client.BuildRequest(ctx).
+		SetQueryParams(map[string]string{"deckCode": code, "owner": owner}).
+		SetBody(cards)
 
-	return resp.Result().(*apiModels.APIResponse), nil
+	return client.Execute[apiModels.APIResponse](request, http.MethodDelete, api.baseUrl+"/content", removeCardsErrors)
 }
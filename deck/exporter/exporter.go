@@ -0,0 +1,242 @@
+package exporter
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	cardModel "github.com/stevezaluk/mtgjson-models/card"
+	deckModel "github.com/stevezaluk/mtgjson-models/deck"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+ErrUnknownFormat - Returned when Export is called with a Format that it does not support
+*/
+var ErrUnknownFormat = errors.New("exporter: unknown decklist format")
+
+/*
+Format - A decklist format that Export can render
+*/
+type Format int
+
+const (
+	// FormatArena - MTG Arena import text, with About, Commander, Deck and Sideboard sections
+	FormatArena Format = iota
+
+	// FormatDek - An MTGO .dek XML file
+	FormatDek
+
+	// FormatCockatrice - A Cockatrice .cod XML file
+	FormatCockatrice
+
+	// FormatCSV - A Moxfield-style CSV file with one row per card and board. This format is export-only, as
+	// importer.Parse cannot read it back
+	FormatCSV
+
+	// FormatMTGJSON - A JSON file in the schema of the deck files published by MTGJSON
+	FormatMTGJSON
+)
+
+/*
+options - The settings collected from each Option passed to Export
+*/
+type options struct {
+	// date - The date written to the metadata of MTGJSON deck files
+	date time.Time
+}
+
+/*
+Option - Configures a call to Export
+*/
+type Option func(opts *options)
+
+/*
+WithDate - Write the date passed in the parameter to the metadata of MTGJSON deck files instead of the current date,
+so that an export can be reproduced exactly
+*/
+func WithDate(date time.Time) Option {
+	return func(opts *options) {
+		opts.date = date
+	}
+}
+
+/*
+entry - A card of a board along with the number of copies of it
+*/
+type entry struct {
+	// card - The card model
+	card *cardModel.CardSet
+
+	// count - The number of copies of the card on the board
+	count int
+}
+
+/*
+board - A board of a deck, grouped into entries
+*/
+type board struct {
+	// name - The name of the board as it appears in the MTGJSON deck schema
+	name string
+
+	// entries - The entries of the board, in the order their cards first appear
+	entries []entry
+}
+
+/*
+Export - Render the deck passed in the parameter in the format passed in the parameter and write it to the writer. The
+deck model supplies the name and code of the deck, while its contents, as returned by DeckAPI.GetDeckContents, supply
+the cards of each board. Copies of the same card, which the contents hold as repeated models, are collapsed into a
+single entry with a count. MTGO and Cockatrice have no commander zone, so the commander is written to the sideboard in
+those formats. The options passed in the parameter only affect the formats that they describe
+*/
+func Export(writer io.Writer, deck *deckModel.Deck, contents *deckModel.DeckContents, format Format, opts ...Option) error {
+	settings := options{date: time.Now()}
+	for _, opt := range opts {
+		opt(&settings)
+	}
+
+	boards := []board{
+		{name: "commander", entries: group(contents.GetCommander())},
+		{name: "mainBoard", entries: group(contents.GetMainBoard())},
+		{name: "sideBoard", entries: group(contents.GetSideBoard())},
+	}
+
+	switch format {
+	case FormatArena:
+		return writeArena(writer, deck, boards)
+	case FormatDek:
+		return writeDek(writer, boards)
+	case FormatCockatrice:
+		return writeCockatrice(writer, deck, boards)
+	case FormatCSV:
+		return writeCSV(writer, boards)
+	case FormatMTGJSON:
+		return writeMTGJSON(writer, deck, boards, settings.date)
+	default:
+		return ErrUnknownFormat
+	}
+}
+
+/*
+group - Collapse the cards passed in the parameter into entries, keyed by MTGJSONv4 ID and kept in the order that each
+card first appears
+*/
+func group(cards []*cardModel.CardSet) []entry {
+	entries := make([]entry, 0, len(cards))
+	index := make(map[string]int)
+
+	for _, card := range cards {
+		key := card.GetIdentifiers().GetMtgjsonV4Id()
+		if key == "" {
+			key = card.GetName()
+		}
+
+		if position, ok := index[key]; ok {
+			entries[position].count++
+			continue
+		}
+
+		index[key] = len(entries)
+		entries = append(entries, entry{card: card, count: 1})
+	}
+
+	return entries
+}
+
+/*
+frontFace - Returns the name that deckbuilding tools know a card by: the name of the front face for double-faced,
+adventure and flip cards, and the full name for split cards, whose halves are equal
+*/
+func frontFace(card *cardModel.CardSet) string {
+	switch card.GetLayout() {
+	case "split", "aftermath":
+		return card.GetName()
+	}
+
+	face, _, _ := strings.Cut(card.GetName(), " // ")
+
+	return face
+}
+
+/*
+writeArena - Write the boards in the format of MTG Arena import text
+*/
+func writeArena(writer io.Writer, deck *deckModel.Deck, boards []board) error {
+	buffer := bufio.NewWriter(writer)
+	headers := map[string]string{"commander": "Commander", "mainBoard": "Deck", "sideBoard": "Sideboard"}
+	separate := false
+
+	if deck.GetName() != "" {
+		fmt.Fprintf(buffer, "About\nName %s\n", deck.GetName())
+		separate = true
+	}
+
+	for _, board := range boards {
+		if len(board.entries) == 0 {
+			continue
+		}
+
+		if separate {
+			buffer.WriteString("\n")
+		}
+
+		buffer.WriteString(headers[board.name] + "\n")
+
+		for _, entry := range board.entries {
+			fmt.Fprintf(buffer, "%d %s", entry.count, frontFace(entry.card))
+
+			if entry.card.GetSetCode() != "" {
+				fmt.Fprintf(buffer, " (%s)", entry.card.GetSetCode())
+
+				if entry.card.GetNumber() != "" {
+					fmt.Fprintf(buffer, " %s", entry.card.GetNumber())
+				}
+			}
+
+			buffer.WriteString("\n")
+		}
+
+		separate = true
+	}
+
+	return buffer.Flush()
+}
+
+/*
+csvBoards - The names that Moxfield gives to each board of the MTGJSON deck schema
+*/
+var csvBoards = map[string]string{"commander": "commanders", "mainBoard": "mainboard", "sideBoard": "sideboard"}
+
+/*
+writeCSV - Write the boards as a Moxfield-style CSV file, with a header row followed by one row per entry
+*/
+func writeCSV(writer io.Writer, boards []board) error {
+	csvWriter := csv.NewWriter(writer)
+
+	if err := csvWriter.Write([]string{"Count", "Name", "Edition", "Collector Number", "Board"}); err != nil {
+		return err
+	}
+
+	for _, board := range boards {
+		for _, entry := range board.entries {
+			err := csvWriter.Write([]string{
+				strconv.Itoa(entry.count),
+				entry.card.GetName(),
+				strings.ToLower(entry.card.GetSetCode()),
+				entry.card.GetNumber(),
+				csvBoards[board.name],
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	csvWriter.Flush()
+
+	return csvWriter.Error()
+}
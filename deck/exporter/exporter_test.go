@@ -0,0 +1,175 @@
+package exporter_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	cardModel "github.com/stevezaluk/mtgjson-models/card"
+	deckModel "github.com/stevezaluk/mtgjson-models/deck"
+	"github.com/stevezaluk/mtgjson-sdk-client/api"
+	"github.com/stevezaluk/mtgjson-sdk-client/deck/exporter"
+	"github.com/stevezaluk/mtgjson-sdk-client/deck/importer"
+	"github.com/stevezaluk/mtgjson-sdk-client/testserver"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+/*
+fixtureCards - The cards of the fixture deck, keyed by MTGJSONv4 ID
+*/
+var fixtureCards = map[string]*cardModel.CardSet{
+	"00000000-0000-0000-0000-000000000001": {Name: "Lightning Bolt", SetCode: "M11", Number: "149"},
+	"00000000-0000-0000-0000-000000000002": {Name: "Mountain", SetCode: "M11", Number: "244"},
+	"00000000-0000-0000-0000-000000000003": {Name: "Fire // Ice", SetCode: "MH2", Number: "290", Layout: "split"},
+	"00000000-0000-0000-0000-000000000004": {Name: "Smash to Smithereens", SetCode: "SOM", Number: "107"},
+}
+
+/*
+fixtureDeck - Returns the fixture deck and its contents, with the cards of each board repeated once per copy
+*/
+func fixtureDeck() (*deckModel.Deck, *deckModel.DeckContents) {
+	deck := &deckModel.Deck{
+		Code: "BURN",
+		Name: "Burn",
+		ContentIds: &deckModel.DeckContentIds{
+			MainBoard: []string{
+				"00000000-0000-0000-0000-000000000001", "00000000-0000-0000-0000-000000000001",
+				"00000000-0000-0000-0000-000000000002", "00000000-0000-0000-0000-000000000002",
+				"00000000-0000-0000-0000-000000000002", "00000000-0000-0000-0000-000000000003",
+			},
+			SideBoard: []string{"00000000-0000-0000-0000-000000000004", "00000000-0000-0000-0000-000000000004"},
+		},
+	}
+
+	contents := &deckModel.DeckContents{}
+	for _, uuid := range deck.ContentIds.MainBoard {
+		contents.MainBoard = append(contents.MainBoard, fixtureCards[uuid])
+	}
+
+	for _, uuid := range deck.ContentIds.SideBoard {
+		contents.SideBoard = append(contents.SideBoard, fixtureCards[uuid])
+	}
+
+	return deck, contents
+}
+
+/*
+newImporter - Start a testserver holding the cards of the fixture deck and return an Importer that resolves names
+against it
+*/
+func newImporter(t *testing.T) *importer.Importer {
+	server := testserver.New(testserver.WithoutAuth())
+	t.Cleanup(server.Close)
+
+	for uuid, card := range fixtureCards {
+		seeded := &cardModel.CardSet{
+			Name:        card.GetName(),
+			SetCode:     card.GetSetCode(),
+			Number:      card.GetNumber(),
+			Layout:      card.GetLayout(),
+			Identifiers: &cardModel.CardIdentifiers{MtgjsonV4Id: uuid},
+		}
+
+		if err := server.AddCard(seeded, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mtgjson, err := api.NewFromURL(server.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return importer.New(mtgjson.Card)
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	deck, contents := fixtureDeck()
+	cards := newImporter(t)
+
+	tests := []struct {
+		name     string
+		export   exporter.Format
+		parse    importer.Format
+		wantName string
+	}{
+		{name: "arena", export: exporter.FormatArena, parse: importer.FormatArena, wantName: "Burn"},
+		{name: "dek", export: exporter.FormatDek, parse: importer.FormatDek},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buffer bytes.Buffer
+			if err := exporter.Export(&buffer, deck, contents, test.export); err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := cards.Import(context.Background(), &buffer, test.parse)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(result.Unresolved) != 0 {
+				t.Fatalf("unresolved lines = %+v, want every card to be re-imported", result.Unresolved)
+			}
+
+			if result.Deck.GetName() != test.wantName {
+				t.Errorf("name = %q, want %q", result.Deck.GetName(), test.wantName)
+			}
+
+			for _, board := range []struct {
+				name string
+				got  []string
+				want []string
+			}{
+				{name: "main board", got: result.Deck.GetContentIds().GetMainBoard(), want: deck.GetContentIds().GetMainBoard()},
+				{name: "sideboard", got: result.Deck.GetContentIds().GetSideBoard(), want: deck.GetContentIds().GetSideBoard()},
+				{name: "commander", got: result.Deck.GetContentIds().GetCommander(), want: deck.GetContentIds().GetCommander()},
+			} {
+				if !slices.Equal(slices.Sorted(slices.Values(board.got)), slices.Sorted(slices.Values(board.want))) {
+					t.Errorf("%s = %v, want %v", board.name, board.got, board.want)
+				}
+			}
+		})
+	}
+}
+
+func TestExportMTGJSONDate(t *testing.T) {
+	deck, contents := fixtureDeck()
+
+	var buffer bytes.Buffer
+	err := exporter.Export(&buffer, deck, contents, exporter.FormatMTGJSON, exporter.WithDate(time.Date(2024, time.March, 5, 23, 0, 0, 0, time.UTC)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var file struct {
+		Meta struct {
+			Date    string `json:"date"`
+			Version string `json:"version"`
+		} `json:"meta"`
+	}
+
+	if err := json.Unmarshal(buffer.Bytes(), &file); err != nil {
+		t.Fatal(err)
+	}
+
+	if file.Meta.Date != "2024-03-05" || file.Meta.Version != exporter.SchemaVersion+"+20240305" {
+		t.Errorf("meta = %+v, want the date passed to WithDate", file.Meta)
+	}
+}
+
+func TestExportDekOmitsMissingCatID(t *testing.T) {
+	deck, contents := fixtureDeck()
+
+	var buffer bytes.Buffer
+	if err := exporter.Export(&buffer, deck, contents, exporter.FormatDek); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(buffer.String(), "CatID") {
+		t.Errorf("the .dek file holds a CatID for cards without an MTGO ID:\n%s", buffer.String())
+	}
+}
@@ -0,0 +1,138 @@
+package exporter
+
+import (
+	"encoding/json"
+	cardModel "github.com/stevezaluk/mtgjson-models/card"
+	deckModel "github.com/stevezaluk/mtgjson-models/deck"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"time"
+)
+
+/*
+SchemaVersion - The version of the MTGJSON schema that deck files are written in. It is recorded in the metadata of each
+file along with the date of the export, in the same form as the files published by MTGJSON
+*/
+const SchemaVersion = "5.2.2"
+
+/*
+mtgjsonFile - The root object of an MTGJSON deck file
+*/
+type mtgjsonFile struct {
+	// Meta - The metadata of the file
+	Meta mtgjsonMeta `json:"meta"`
+
+	// Data - The deck
+	Data mtgjsonDeck `json:"data"`
+}
+
+/*
+mtgjsonMeta - The metadata object of an MTGJSON file
+*/
+type mtgjsonMeta struct {
+	// Date - The date the file was written, in ISO 8601 format
+	Date string `json:"date"`
+
+	// Version - The version of the MTGJSON schema the file was written in, suffixed with the date of the file
+	Version string `json:"version"`
+}
+
+/*
+mtgjsonDeck - A deck in the MTGJSON deck schema. Each board holds card objects with an additional count field
+*/
+type mtgjsonDeck struct {
+	// Code - The code of the deck
+	Code string `json:"code"`
+
+	// Name - The name of the deck
+	Name string `json:"name"`
+
+	// ReleaseDate - The release date of the deck, or null if it is not set
+	ReleaseDate *string `json:"releaseDate"`
+
+	// Type - The type of the deck
+	Type string `json:"type"`
+
+	// Commander - The cards of the commander zone, omitted if empty
+	Commander []map[string]any `json:"commander,omitempty"`
+
+	// MainBoard - The cards of the main deck
+	MainBoard []map[string]any `json:"mainBoard"`
+
+	// SideBoard - The cards of the sideboard
+	SideBoard []map[string]any `json:"sideBoard"`
+}
+
+/*
+writeMTGJSON - Write the deck and its boards as an MTGJSON deck file dated with the date passed in the parameter. The
+API metadata of the cards is not written
+*/
+func writeMTGJSON(writer io.Writer, deck *deckModel.Deck, boards []board, date time.Time) error {
+	date = date.UTC()
+
+	file := mtgjsonFile{
+		Meta: mtgjsonMeta{
+			Date:    date.Format(time.DateOnly),
+			Version: SchemaVersion + "+" + date.Format("20060102"),
+		},
+		Data: mtgjsonDeck{
+			Code: deck.GetCode(),
+			Name: deck.GetName(),
+			Type: deck.GetType(),
+		},
+	}
+
+	if releaseDate := deck.GetReleaseDate(); releaseDate != "" {
+		file.Data.ReleaseDate = &releaseDate
+	}
+
+	for _, board := range boards {
+		cards := make([]map[string]any, 0, len(board.entries))
+
+		for _, entry := range board.entries {
+			card, err := cardObject(entry)
+			if err != nil {
+				return err
+			}
+
+			cards = append(cards, card)
+		}
+
+		switch board.name {
+		case "commander":
+			file.Data.Commander = cards
+		case "mainBoard":
+			file.Data.MainBoard = cards
+		case "sideBoard":
+			file.Data.SideBoard = cards
+		}
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+
+	return encoder.Encode(file)
+}
+
+/*
+cardObject - Returns the card of the entry as a JSON object with its count added, as the MTGJSON deck schema expects
+*/
+func cardObject(entry entry) (map[string]any, error) {
+	card := proto.Clone(entry.card).(*cardModel.CardSet)
+	card.MtgjsonApiMeta = nil
+
+	data, err := json.Marshal(card)
+	if err != nil {
+		return nil, err
+	}
+
+	object := make(map[string]any)
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+
+	object["count"] = entry.count
+
+	return object, nil
+}
@@ -0,0 +1,150 @@
+package exporter
+
+import (
+	"encoding/xml"
+	deckModel "github.com/stevezaluk/mtgjson-models/deck"
+	"io"
+)
+
+/*
+dekFile - The root element of an MTGO .dek file
+*/
+type dekFile struct {
+	XMLName xml.Name `xml:"Deck"`
+
+	// NetDeckID - Always 0 for decks that were not downloaded through MTGO
+	NetDeckID int `xml:"NetDeckID"`
+
+	// PreconstructedDeckID - Always 0 for decks that are not preconstructed products
+	PreconstructedDeckID int `xml:"PreconstructedDeckID"`
+
+	// Cards - One element per entry of the main deck and sideboard
+	Cards []dekCard `xml:"Cards"`
+}
+
+/*
+dekCard - A Cards element of an MTGO .dek file
+*/
+type dekCard struct {
+	// CatID - The MTGO ID of the printing, which MTGO uses to pick the printing. Omitted if the card has none
+	CatID string `xml:"CatID,attr,omitempty"`
+
+	// Quantity - The number of copies of the card
+	Quantity int `xml:"Quantity,attr"`
+
+	// Sideboard - True if the card is in the sideboard
+	Sideboard bool `xml:"Sideboard,attr"`
+
+	// Name - The name of the card
+	Name string `xml:"Name,attr"`
+
+	// Annotation - Always 0
+	Annotation int `xml:"Annotation,attr"`
+}
+
+/*
+codFile - The root element of a Cockatrice .cod file
+*/
+type codFile struct {
+	XMLName xml.Name `xml:"cockatrice_deck"`
+
+	// Version - The version of the file format, always 1
+	Version int `xml:"version,attr"`
+
+	// Name - The name of the deck
+	Name string `xml:"deckname"`
+
+	// Comments - Free text shown alongside the deck, always empty
+	Comments string `xml:"comments"`
+
+	// Zones - The main and side zones of the deck
+	Zones []codZone `xml:"zone"`
+}
+
+/*
+codZone - A zone element of a Cockatrice .cod file
+*/
+type codZone struct {
+	// Name - The name of the zone, main or side
+	Name string `xml:"name,attr"`
+
+	// Cards - One element per entry of the zone
+	Cards []codCard `xml:"card"`
+}
+
+/*
+codCard - A card element of a Cockatrice .cod file
+*/
+type codCard struct {
+	// Number - The number of copies of the card
+	Number int `xml:"number,attr"`
+
+	// Name - The name of the card
+	Name string `xml:"name,attr"`
+}
+
+/*
+writeDek - Write the boards as an MTGO .dek file. The commander is written to the sideboard
+*/
+func writeDek(writer io.Writer, boards []board) error {
+	file := dekFile{}
+
+	for _, board := range boards {
+		for _, entry := range board.entries {
+			file.Cards = append(file.Cards, dekCard{
+				CatID:     entry.card.GetIdentifiers().GetMtgoId(),
+				Quantity:  entry.count,
+				Sideboard: board.name != "mainBoard",
+				Name:      frontFace(entry.card),
+			})
+		}
+	}
+
+	return writeXML(writer, file)
+}
+
+/*
+writeCockatrice - Write the boards as a Cockatrice .cod file. The commander is written to the side zone
+*/
+func writeCockatrice(writer io.Writer, deck *deckModel.Deck, boards []board) error {
+	main := codZone{Name: "main"}
+	side := codZone{Name: "side"}
+
+	for _, board := range boards {
+		zone := &side
+		if board.name == "mainBoard" {
+			zone = &main
+		}
+
+		for _, entry := range board.entries {
+			zone.Cards = append(zone.Cards, codCard{Number: entry.count, Name: frontFace(entry.card)})
+		}
+	}
+
+	file := codFile{Version: 1, Name: deck.GetName(), Zones: []codZone{main}}
+	if len(side.Cards) != 0 {
+		file.Zones = append(file.Zones, side)
+	}
+
+	return writeXML(writer, file)
+}
+
+/*
+writeXML - Write the value passed in the parameter as an indented XML document
+*/
+func writeXML(writer io.Writer, value any) error {
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "  ")
+
+	if err := encoder.Encode(value); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(writer, "\n")
+
+	return err
+}
@@ -0,0 +1,336 @@
+package importer
+
+import (
+	"context"
+	cardModel "github.com/stevezaluk/mtgjson-models/card"
+	deckModel "github.com/stevezaluk/mtgjson-models/deck"
+	sdkErrors "github.com/stevezaluk/mtgjson-models/errors"
+	"github.com/stevezaluk/mtgjson-sdk-client/api"
+	"github.com/stevezaluk/mtgjson-sdk-client/card"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+/*
+Unresolved - A line of a decklist that did not make it into the imported deck
+*/
+type Unresolved struct {
+	// Line - The line of the decklist, starting at 1
+	Line int
+
+	// Text - The line as it appears in the decklist
+	Text string
+
+	// Err - Why the line was not imported: ErrInvalidLine if it could not be parsed, or ErrNoCard if no card matches
+	// its name
+	Err error
+}
+
+/*
+Result - The result of an import
+*/
+type Result struct {
+	// Deck - The imported deck, with the content IDs of each board populated. Its name is only set if the decklist
+	// carries one, and its code is never set, so both must be filled in before the deck is passed to NewDeck
+	Deck *deckModel.Deck
+
+	// Unresolved - The lines of the decklist that were not imported, ordered by line
+	Unresolved []Unresolved
+}
+
+/*
+lookup - The printings found for a unique entry of a decklist while paging through the cards
+*/
+type lookup struct {
+	// entry - The entry being resolved, with its name lowercased
+	entry Entry
+
+	// exact - The MTGJSONv4 ID of the printing the entry names. For an entry without a set code this is the first
+	// printing found
+	exact string
+
+	// inSet - The MTGJSONv4 ID of the first printing found in the set of the entry, used when no printing has its
+	// collector number
+	inSet string
+
+	// any - The MTGJSONv4 ID of the first printing found in any set, used when the set of the entry has none
+	any string
+}
+
+/*
+add - Record the card passed in the parameter, whose name or front face name has already been matched to the entry
+*/
+func (lookup *lookup) add(result *cardModel.CardSet) {
+	uuid := result.GetIdentifiers().GetMtgjsonV4Id()
+
+	if lookup.any == "" {
+		lookup.any = uuid
+	}
+
+	if lookup.entry.SetCode == "" {
+		lookup.exact = lookup.any
+		return
+	}
+
+	if !strings.EqualFold(result.GetSetCode(), lookup.entry.SetCode) {
+		return
+	}
+
+	if lookup.inSet == "" {
+		lookup.inSet = uuid
+	}
+
+	if lookup.exact == "" && (lookup.entry.Number == "" || result.GetNumber() == lookup.entry.Number) {
+		lookup.exact = uuid
+	}
+}
+
+/*
+done - Returns true once the printing the entry names has been found, so that no later card can improve on it
+*/
+func (lookup *lookup) done() bool {
+	return lookup.exact != ""
+}
+
+/*
+uuid - Returns the MTGJSONv4 ID that the entry resolved to, falling back from the exact printing to any printing in the
+set of the entry and then to any printing at all. Returns ErrNoCard if no card matched
+*/
+func (lookup *lookup) uuid() (string, error) {
+	for _, uuid := range []string{lookup.exact, lookup.inSet, lookup.any} {
+		if uuid != "" {
+			return uuid, nil
+		}
+	}
+
+	return "", sdkErrors.ErrNoCard
+}
+
+/*
+Importer - Turns decklists in the formats supported by Parse into deck models, resolving the name of each card to the
+MTGJSONv4 ID of a printing through a CardService
+*/
+type Importer struct {
+	// cards - The service card names are resolved with
+	cards api.CardService
+}
+
+/*
+New - Create a new Importer that resolves card names with the CardService passed in the parameter. Usually this is
+the Card field of an api.MtgjsonAPI, or an offline.CardAPI to import without a server
+*/
+func New(cards api.CardService) *Importer {
+	return &Importer{cards: cards}
+}
+
+/*
+ImportFile - Import the decklist at the path passed in the parameter. Files with a .dek extension are parsed as MTGO
+.dek files, while the format of any other file is detected from its contents, see Import
+*/
+func (importer *Importer) ImportFile(ctx context.Context, path string) (*Result, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	format := FormatAuto
+	if strings.EqualFold(filepath.Ext(path), ".dek") {
+		format = FormatDek
+	}
+
+	return importer.Import(ctx, file, format)
+}
+
+/*
+Import - Parse the decklist read from the reader passed in the parameter and resolve each of its entries, see Parse
+and Resolve
+*/
+func (importer *Importer) Import(ctx context.Context, reader io.Reader, format Format) (*Result, error) {
+	decklist, err := Parse(reader, format)
+	if err != nil {
+		return nil, err
+	}
+
+	return importer.Resolve(ctx, decklist)
+}
+
+/*
+Resolve - Resolve the entries of a parsed decklist to MTGJSONv4 IDs and build a deck from them, adding one content ID
+per copy of a card to its board. An entry naming a set code and collector number resolves to that printing; if the
+printing cannot be found, for example because Arena uses a different code for the set, any printing of the card is
+used instead. The names of double-faced and split cards may be given as either the full name or the name of their
+front face. Entries that do not match any card, along with the lines that could not be parsed, are reported in the
+Unresolved field of the Result. An error is only returned if searching for a card fails.
+
+Each unique name is searched for with a name query, so that the CardService only returns the printings of that
+card, and a name that matches no card is searched for again as a substring to find the double-faced or split card it
+is a face of. If the CardService yields cards that do not match a query, because it cannot filter by name, the
+remaining entries are instead resolved in a single pass over all of its cards, which stops as soon as the printing of
+every entry has been found
+*/
+func (importer *Importer) Resolve(ctx context.Context, decklist *Decklist) (*Result, error) {
+	lookups, err := importer.lookup(ctx, decklist.Entries)
+	if err != nil {
+		return nil, err
+	}
+
+	contentIds := &deckModel.DeckContentIds{}
+	unresolved := slices.Clone(decklist.Invalid)
+
+	for _, entry := range decklist.Entries {
+		uuid, err := lookups[lookupKey(entry)].uuid()
+		if err != nil {
+			unresolved = append(unresolved, Unresolved{Line: entry.Line, Text: entry.Text, Err: err})
+			continue
+		}
+
+		board := &contentIds.MainBoard
+		switch entry.Board {
+		case BoardSide:
+			board = &contentIds.SideBoard
+		case BoardCommander:
+			board = &contentIds.Commander
+		}
+
+		for range entry.Quantity {
+			*board = append(*board, uuid)
+		}
+	}
+
+	slices.SortStableFunc(unresolved, func(a Unresolved, b Unresolved) int {
+		return a.Line - b.Line
+	})
+
+	return &Result{
+		Deck:       &deckModel.Deck{Name: decklist.Name, ContentIds: contentIds},
+		Unresolved: unresolved,
+	}, nil
+}
+
+/*
+lookupKey - Returns the key that an entry is looked up under. Entries naming the same card and printing share a key,
+whatever their board, quantity or the case of their name
+*/
+func lookupKey(entry Entry) Entry {
+	return Entry{Name: strings.ToLower(entry.Name), SetCode: entry.SetCode, Number: entry.Number}
+}
+
+/*
+lookup - Search for the printings of each unique entry passed in the parameter, one name at a time. Falls back to a
+single pass over every card if the CardService cannot filter by name
+*/
+func (importer *Importer) lookup(ctx context.Context, entries []Entry) (map[Entry]*lookup, error) {
+	lookups := make(map[Entry]*lookup)
+	byName := make(map[string][]*lookup)
+	var names []string
+
+	for _, entry := range entries {
+		key := lookupKey(entry)
+		if _, ok := lookups[key]; ok {
+			continue
+		}
+
+		if _, ok := byName[key.Name]; !ok {
+			names = append(names, key.Name)
+		}
+
+		lookups[key] = &lookup{entry: key}
+		byName[key.Name] = append(byName[key.Name], lookups[key])
+	}
+
+	for _, name := range names {
+		named := map[string][]*lookup{name: byName[name]}
+
+		filtered, err := importer.search(ctx, card.NewQuery().Name(name), named)
+		if err != nil {
+			return nil, err
+		}
+
+		if filtered && byName[name][0].any == "" {
+			// the name may be that of a face, which a name query does not match
+			filtered, err = importer.search(ctx, card.NewQuery().NameContains(name), named)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if !filtered {
+			if _, err := importer.search(ctx, nil, byName); err != nil {
+				return nil, err
+			}
+
+			break
+		}
+	}
+
+	return lookups, nil
+}
+
+/*
+search - Page through the cards of the CardService that match the query passed in the parameter, recording each one
+against the lookups of the names it may be entered under, and stop as soon as every lookup has found the printing it
+names. Returns false, having stopped at the first card that does not match the query, if the CardService did not
+apply it
+*/
+func (importer *Importer) search(ctx context.Context, query *card.CardQuery, byName map[string][]*lookup) (bool, error) {
+	remaining := 0
+	for _, named := range byName {
+		for _, lookup := range named {
+			if !lookup.done() {
+				remaining++
+			}
+		}
+	}
+
+	if remaining == 0 {
+		return true, nil
+	}
+
+	for result, err := range importer.cards.Search(ctx, query, 0) {
+		if err != nil {
+			return false, err
+		}
+
+		if !query.Matches(result) {
+			return false, nil
+		}
+
+		for _, name := range cardNames(result) {
+			for _, lookup := range byName[name] {
+				if lookup.done() {
+					continue
+				}
+
+				lookup.add(result)
+				if lookup.done() {
+					remaining--
+				}
+			}
+		}
+
+		if remaining == 0 {
+			break
+		}
+	}
+
+	return true, nil
+}
+
+/*
+cardNames - Returns the lowercased names that an entry may use for the card: its full name and, for double-faced and
+split cards, the name of each of its faces
+*/
+func cardNames(result *cardModel.CardSet) []string {
+	name := strings.ToLower(result.GetName())
+
+	faces := strings.Split(name, " // ")
+	if len(faces) == 1 {
+		return faces
+	}
+
+	return append(faces, name)
+}
@@ -0,0 +1,161 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	cardModel "github.com/stevezaluk/mtgjson-models/card"
+	sdkErrors "github.com/stevezaluk/mtgjson-models/errors"
+	"github.com/stevezaluk/mtgjson-sdk-client/api"
+	"github.com/stevezaluk/mtgjson-sdk-client/card"
+	"iter"
+	"slices"
+	"strings"
+	"testing"
+)
+
+/*
+countingCards - A CardService whose Search yields a fixed list of cards and counts how many it was asked for
+*/
+type countingCards struct {
+	api.CardService
+
+	// cards - The cards yielded by Search, in order
+	cards []*cardModel.CardSet
+
+	// unfiltered - Yield every card whatever the query, like a service that cannot filter
+	unfiltered bool
+
+	// searches - The number of times Search was called
+	searches int
+
+	// yielded - The number of cards Search yielded
+	yielded int
+}
+
+/*
+Search - Yields each card that matches the query, or every card if the service is unfiltered, counting the searches
+and cards
+*/
+func (service *countingCards) Search(ctx context.Context, query *card.CardQuery, pageSize int) iter.Seq2[*cardModel.CardSet, error] {
+	service.searches++
+
+	return func(yield func(*cardModel.CardSet, error) bool) {
+		for _, result := range service.cards {
+			if !service.unfiltered && !query.Matches(result) {
+				continue
+			}
+
+			service.yielded++
+			if !yield(result, nil) {
+				return
+			}
+		}
+	}
+}
+
+/*
+printing - Returns a card model for a printing of a card
+*/
+func printing(uuid string, name string, setCode string, number string) *cardModel.CardSet {
+	return &cardModel.CardSet{
+		Name:        name,
+		SetCode:     setCode,
+		Number:      number,
+		Identifiers: &cardModel.CardIdentifiers{MtgjsonV4Id: uuid},
+	}
+}
+
+func TestResolve(t *testing.T) {
+	service := &countingCards{cards: []*cardModel.CardSet{
+		printing("bolt-m10", "Lightning Bolt", "M10", "146"),
+		printing("bolt-m11", "Lightning Bolt", "M11", "149"),
+		printing("bolt-m11-promo", "Lightning Bolt", "M11", "149p"),
+		printing("fire-ice", "Fire // Ice", "MH2", "290"),
+		printing("mountain", "Mountain", "M11", "244"),
+		printing("krenko", "Krenko, Mob Boss", "M13", "139"),
+	}}
+
+	tests := []struct {
+		name    string
+		line    string
+		want    string
+		wantErr error
+	}{
+		{name: "any printing", line: "1 Lightning Bolt", want: "bolt-m10"},
+		{name: "exact printing", line: "1 Lightning Bolt (M11) 149p", want: "bolt-m11-promo"},
+		{name: "unknown collector number", line: "1 Lightning Bolt (M11) 999", want: "bolt-m11"},
+		{name: "unknown set", line: "1 Lightning Bolt (XYZ) 1", want: "bolt-m10"},
+		{name: "front face", line: "1 fire", want: "fire-ice"},
+		{name: "full split name", line: "1 FIRE // ICE", want: "fire-ice"},
+		{name: "unknown card", line: "1 Lightning Helix", wantErr: sdkErrors.ErrNoCard},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := New(service).Import(context.Background(), strings.NewReader(test.line), FormatPlain)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if test.wantErr != nil {
+				if len(result.Unresolved) != 1 || !errors.Is(result.Unresolved[0].Err, test.wantErr) {
+					t.Errorf("unresolved = %+v, want the line reported with %v", result.Unresolved, test.wantErr)
+				}
+
+				return
+			}
+
+			if got := result.Deck.GetContentIds().GetMainBoard(); !slices.Equal(got, []string{test.want}) {
+				t.Errorf("main board = %v, want [%s]", got, test.want)
+			}
+		})
+	}
+}
+
+func TestResolveSearchesByName(t *testing.T) {
+	cards := []*cardModel.CardSet{
+		printing("bolt", "Lightning Bolt", "M11", "149"),
+		printing("mountain", "Mountain", "M11", "244"),
+		printing("shock", "Shock", "M19", "156"),
+		printing("helix", "Lightning Helix", "RAV", "213"),
+		printing("fire-ice", "Fire // Ice", "MH2", "290"),
+	}
+
+	decklist := "4 Lightning Bolt\n20 Mountain\n2 lightning bolt\n1 Ice\n\nSideboard\n2 Mountain (M11) 244"
+
+	tests := []struct {
+		name         string
+		unfiltered   bool
+		wantSearches int
+		wantYielded  int
+	}{
+		// a name query each for the bolt and the mountains, and a name and substring query for the face
+		{name: "service that filters", wantSearches: 4, wantYielded: 3},
+		// the bolt happens to come first, but the query for the mountains yields it too, so a single pass resolves the
+		// rest
+		{name: "service that cannot filter", unfiltered: true, wantSearches: 3, wantYielded: 7},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := &countingCards{cards: cards, unfiltered: test.unfiltered}
+
+			result, err := New(service).Import(context.Background(), strings.NewReader(decklist), FormatPlain)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(result.Deck.GetContentIds().GetMainBoard()) != 27 || len(result.Deck.GetContentIds().GetSideBoard()) != 2 {
+				t.Errorf("boards = %v, want 27 main board and 2 sideboard cards", result.Deck.GetContentIds())
+			}
+
+			if len(result.Unresolved) != 0 {
+				t.Errorf("unresolved = %+v, want every entry resolved", result.Unresolved)
+			}
+
+			if service.searches != test.wantSearches || service.yielded != test.wantYielded {
+				t.Errorf("searches = %d, cards read = %d, want %d and %d", service.searches, service.yielded, test.wantSearches, test.wantYielded)
+			}
+		})
+	}
+}
@@ -0,0 +1,386 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+/*
+ErrInvalidLine - Recorded for a line of a decklist that is neither a card entry, a section header nor a comment
+*/
+var ErrInvalidLine = errors.New("importer: line is not a card entry")
+
+/*
+ErrUnknownFormat - Returned when Parse is called with a Format that it does not support
+*/
+var ErrUnknownFormat = errors.New("importer: unknown decklist format")
+
+/*
+Format - A decklist format understood by Parse
+*/
+type Format int
+
+const (
+	// FormatAuto - Detect the format from the contents of the decklist
+	FormatAuto Format = iota
+
+	// FormatArena - An MTG Arena export, with About, Commander, Companion, Deck and Sideboard sections and entries
+	// in the form "4 Lightning Bolt (M11) 149"
+	FormatArena
+
+	// FormatMTGO - An MTGO .txt export, where the sideboard is separated from the main deck by a blank line
+	FormatMTGO
+
+	// FormatDek - An MTGO .dek XML export
+	FormatDek
+
+	// FormatPlain - A list of "N Card Name" entries. Section headers such as Sideboard: and Commander: and the "SB:"
+	// prefix are recognised, while blank lines are ignored
+	FormatPlain
+)
+
+/*
+Board - The board of a deck that an entry belongs to
+*/
+type Board int
+
+const (
+	// BoardMain - The main deck
+	BoardMain Board = iota
+
+	// BoardSide - The sideboard, which also holds the companion of an Arena export
+	BoardSide
+
+	// BoardCommander - The commander zone
+	BoardCommander
+)
+
+/*
+Entry - A single card entry of a decklist
+*/
+type Entry struct {
+	// Line - The line of the decklist the entry was read from, starting at 1
+	Line int
+
+	// Text - The entry as it appears in the decklist
+	Text string
+
+	// Quantity - The number of copies of the card
+	Quantity int
+
+	// Name - The name of the card
+	Name string
+
+	// SetCode - The code of the set of the printing, if the decklist names one
+	SetCode string
+
+	// Number - The collector number of the printing, if the decklist names one
+	Number string
+
+	// Board - The board the entry belongs to
+	Board Board
+}
+
+/*
+Decklist - A parsed decklist whose card names have not been resolved yet
+*/
+type Decklist struct {
+	// Name - The name of the deck, if the decklist carries one (the About section of an Arena export)
+	Name string
+
+	// Entries - The card entries, in the order they appear
+	Entries []Entry
+
+	// Invalid - The lines that could not be parsed, recorded with ErrInvalidLine
+	Invalid []Unresolved
+}
+
+/*
+entryPattern - Matches a card entry: a quantity, optionally followed by an x, the card name and optionally the set code
+in parentheses and the collector number of an Arena export
+*/
+var entryPattern = regexp.MustCompile(`^(\d+)[xX]?\s+(.+?)(?:\s+\(([A-Za-z0-9]+)\)(?:\s+(\S+))?)?$`)
+
+/*
+sections - The section headers recognised in text decklists, lowercased and without a trailing colon, mapped to the
+board that the entries after them belong to. A nil board marks a section whose entries are not part of the deck
+*/
+var sections = map[string]*Board{
+	"deck":        boardOf(BoardMain),
+	"main":        boardOf(BoardMain),
+	"maindeck":    boardOf(BoardMain),
+	"main deck":   boardOf(BoardMain),
+	"mainboard":   boardOf(BoardMain),
+	"sideboard":   boardOf(BoardSide),
+	"side":        boardOf(BoardSide),
+	"companion":   boardOf(BoardSide),
+	"commander":   boardOf(BoardCommander),
+	"commanders":  boardOf(BoardCommander),
+	"about":       nil,
+	"maybeboard":  nil,
+	"considering": nil,
+}
+
+/*
+boardOf - Returns a pointer to the board passed in the parameter
+*/
+func boardOf(board Board) *Board {
+	return &board
+}
+
+/*
+Parse - Parse the decklist read from the reader passed in the parameter. With FormatAuto, MTGO .dek files are
+recognised by their XML, MTGO .txt files by a blank line between two groups of entries without any section headers,
+where the second group holds no more cards than a sideboard may, and anything else is parsed as an Arena export or plain list, which share the same syntax. Lines that cannot be
+parsed are recorded in the Invalid field of the Decklist rather than failing the parse
+*/
+func Parse(reader io.Reader, format Format) (*Decklist, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == FormatAuto {
+		format = detectFormat(data)
+	}
+
+	switch format {
+	case FormatDek:
+		return parseDek(data)
+	case FormatMTGO:
+		return parseText(data, true)
+	case FormatArena, FormatPlain:
+		return parseText(data, false)
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+/*
+maxSideboardSize - The largest number of cards that the second group of an MTGO .txt export may hold
+*/
+const maxSideboardSize = 15
+
+/*
+detectFormat - Returns the format of the decklist passed in the parameter, see Parse
+*/
+func detectFormat(data []byte) Format {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("<")) {
+		return FormatDek
+	}
+
+	// groups - The number of cards in each group of entries separated by blank lines
+	var groups []int
+	blankAfterEntry := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "":
+			blankAfterEntry = len(groups) != 0
+		case isHeader(line) || hasSideboardPrefix(line):
+			return FormatPlain
+		case entryPattern.MatchString(line):
+			if blankAfterEntry || len(groups) == 0 {
+				groups = append(groups, 0)
+				blankAfterEntry = false
+			}
+
+			quantity, _ := strconv.Atoi(entryPattern.FindStringSubmatch(line)[1])
+			groups[len(groups)-1] += quantity
+		}
+	}
+
+	// a list grouped by card type, such as creatures, spells and lands, also separates its groups with blank lines
+	if len(groups) == 2 && groups[1] <= maxSideboardSize {
+		return FormatMTGO
+	}
+
+	return FormatPlain
+}
+
+/*
+parseText - Parse an Arena, MTGO .txt or plain decklist. If blankStartsSideboard is true then the first blank line
+after an entry of the main deck starts the sideboard, as it does in MTGO exports
+*/
+func parseText(data []byte, blankStartsSideboard bool) (*Decklist, error) {
+	decklist := &Decklist{}
+	board := boardOf(BoardMain)
+	section := ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "":
+			if blankStartsSideboard && board != nil && *board == BoardMain && len(decklist.Entries) != 0 {
+				board = boardOf(BoardSide)
+			}
+
+			continue
+		case strings.HasPrefix(line, "//") || strings.HasPrefix(line, "#"):
+			continue
+		case isHeader(line):
+			section = headerName(line)
+			board = sections[section]
+			continue
+		case section == "about":
+			if name, ok := strings.CutPrefix(line, "Name "); ok {
+				decklist.Name = strings.TrimSpace(name)
+			}
+
+			continue
+		case board == nil:
+			continue
+		}
+
+		entryBoard := *board
+		if text, ok := cutSideboardPrefix(line); ok {
+			line = text
+			entryBoard = BoardSide
+		}
+
+		entry, ok := parseEntry(line)
+		if !ok {
+			decklist.Invalid = append(decklist.Invalid, Unresolved{Line: number, Text: line, Err: ErrInvalidLine})
+			continue
+		}
+
+		entry.Line = number
+		entry.Board = entryBoard
+		decklist.Entries = append(decklist.Entries, entry)
+	}
+
+	return decklist, scanner.Err()
+}
+
+/*
+parseEntry - Parse a single card entry. Returns false if the line is not a card entry
+*/
+func parseEntry(line string) (Entry, bool) {
+	match := entryPattern.FindStringSubmatch(line)
+	if match == nil {
+		return Entry{}, false
+	}
+
+	quantity, err := strconv.Atoi(match[1])
+	if err != nil || quantity == 0 {
+		return Entry{}, false
+	}
+
+	return Entry{
+		Text:     line,
+		Quantity: quantity,
+		Name:     strings.TrimSpace(match[2]),
+		SetCode:  strings.ToUpper(match[3]),
+		Number:   match[4],
+	}, true
+}
+
+/*
+isHeader - Returns true if the line passed in the parameter is a recognised section header
+*/
+func isHeader(line string) bool {
+	_, ok := sections[headerName(line)]
+	return ok
+}
+
+/*
+headerName - Returns the line passed in the parameter lowercased and without a trailing colon
+*/
+func headerName(line string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimSuffix(line, ":")))
+}
+
+/*
+hasSideboardPrefix - Returns true if the line passed in the parameter is a sideboard entry marked with "SB:"
+*/
+func hasSideboardPrefix(line string) bool {
+	_, ok := cutSideboardPrefix(line)
+	return ok
+}
+
+/*
+cutSideboardPrefix - Returns the line passed in the parameter without its "SB:" prefix, and whether it had one
+*/
+func cutSideboardPrefix(line string) (string, bool) {
+	if len(line) < 3 || !strings.EqualFold(line[:3], "SB:") {
+		return line, false
+	}
+
+	return strings.TrimSpace(line[3:]), true
+}
+
+/*
+dekCard - A Cards element of an MTGO .dek file
+*/
+type dekCard struct {
+	// Quantity - The number of copies of the card
+	Quantity int `xml:"Quantity,attr"`
+
+	// Sideboard - True if the card is in the sideboard
+	Sideboard bool `xml:"Sideboard,attr"`
+
+	// Name - The name of the card
+	Name string `xml:"Name,attr"`
+}
+
+/*
+parseDek - Parse an MTGO .dek file. The line of each entry is the line its Cards element ends on
+*/
+func parseDek(data []byte) (*Decklist, error) {
+	decklist := &Decklist{}
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return decklist, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "Cards" {
+			continue
+		}
+
+		var card dekCard
+		if err := decoder.DecodeElement(&card, &start); err != nil {
+			return nil, err
+		}
+
+		line, _ := decoder.InputPos()
+		text := fmt.Sprintf("%d %s", card.Quantity, card.Name)
+
+		if card.Quantity <= 0 || card.Name == "" {
+			decklist.Invalid = append(decklist.Invalid, Unresolved{Line: line, Text: text, Err: ErrInvalidLine})
+			continue
+		}
+
+		board := BoardMain
+		if card.Sideboard {
+			board = BoardSide
+		}
+
+		decklist.Entries = append(decklist.Entries, Entry{
+			Line:     line,
+			Text:     text,
+			Quantity: card.Quantity,
+			Name:     card.Name,
+			Board:    board,
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+/*
+summarize - Returns each entry of the decklist as "board quantity name (set) number", for comparing in tests
+*/
+func summarize(decklist *Decklist) []string {
+	boards := map[Board]string{BoardMain: "main", BoardSide: "side", BoardCommander: "commander"}
+
+	summary := make([]string, 0, len(decklist.Entries))
+	for _, entry := range decklist.Entries {
+		summary = append(summary, fmt.Sprintf("%s %d %s (%s) %s", boards[entry.Board], entry.Quantity, entry.Name, entry.SetCode, entry.Number))
+	}
+
+	return summary
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		decklist    string
+		format      Format
+		wantName    string
+		wantEntries []string
+		wantInvalid []int
+	}{
+		{
+			name: "arena",
+			decklist: `About
+Name Mono Red
+
+Commander
+1 Krenko, Mob Boss (M13) 139
+
+Deck
+4 Lightning Bolt (m11) 149
+2 Fire // Ice (MH2) 290
+
+Sideboard
+3 Smash to Smithereens (SOM) 107`,
+			wantName: "Mono Red",
+			wantEntries: []string{
+				"commander 1 Krenko, Mob Boss (M13) 139",
+				"main 4 Lightning Bolt (M11) 149",
+				"main 2 Fire // Ice (MH2) 290",
+				"side 3 Smash to Smithereens (SOM) 107",
+			},
+		},
+		{
+			name:     "mtgo text",
+			decklist: "4 Lightning Bolt\n20 Mountain\n\n3 Smash to Smithereens\n",
+			wantEntries: []string{
+				"main 4 Lightning Bolt () ",
+				"main 20 Mountain () ",
+				"side 3 Smash to Smithereens () ",
+			},
+		},
+		{
+			name:     "plain list grouped by card type",
+			decklist: "4 Goblin Guide\n4 Monastery Swiftspear\n\n4 Lightning Bolt\n4 Lava Spike\n\n20 Mountain\n",
+			wantEntries: []string{
+				"main 4 Goblin Guide () ",
+				"main 4 Monastery Swiftspear () ",
+				"main 4 Lightning Bolt () ",
+				"main 4 Lava Spike () ",
+				"main 20 Mountain () ",
+			},
+		},
+		{
+			name:        "second group too large for a sideboard",
+			decklist:    "4 Lightning Bolt\n\n20 Mountain\n",
+			wantEntries: []string{"main 4 Lightning Bolt () ", "main 20 Mountain () "},
+		},
+		{
+			name:     "plain with prefixes and comments",
+			decklist: "// Burn\n4x Lightning Bolt\nSB: 2 Pyroblast\n# notes\nMaybeboard\n1 Fireball",
+			wantEntries: []string{
+				"main 4 Lightning Bolt () ",
+				"side 2 Pyroblast () ",
+			},
+		},
+		{
+			name:        "invalid lines",
+			decklist:    "4 Lightning Bolt\nLightning Bolt\n0 Shock\n",
+			wantEntries: []string{"main 4 Lightning Bolt () "},
+			wantInvalid: []int{2, 3},
+		},
+		{
+			name: "dek",
+			decklist: `<?xml version="1.0" encoding="utf-8"?>
+<Deck>
+  <Cards CatID="1" Quantity="4" Sideboard="false" Name="Lightning Bolt" />
+  <Cards CatID="2" Quantity="3" Sideboard="true" Name="Smash to Smithereens" />
+  <Cards CatID="3" Quantity="0" Sideboard="false" Name="Shock" />
+</Deck>`,
+			wantEntries: []string{
+				"main 4 Lightning Bolt () ",
+				"side 3 Smash to Smithereens () ",
+			},
+			wantInvalid: []int{5},
+		},
+		{
+			name:        "blank lines are ignored in plain lists",
+			decklist:    "4 Lightning Bolt\n\n20 Mountain\n",
+			format:      FormatPlain,
+			wantEntries: []string{"main 4 Lightning Bolt () ", "main 20 Mountain () "},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			decklist, err := Parse(strings.NewReader(test.decklist), test.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if decklist.Name != test.wantName {
+				t.Errorf("name = %q, want %q", decklist.Name, test.wantName)
+			}
+
+			if got := summarize(decklist); !slices.Equal(got, test.wantEntries) {
+				t.Errorf("entries = %q, want %q", got, test.wantEntries)
+			}
+
+			var invalid []int
+			for _, line := range decklist.Invalid {
+				if !errors.Is(line.Err, ErrInvalidLine) {
+					t.Errorf("line %d error = %v, want ErrInvalidLine", line.Line, line.Err)
+				}
+
+				invalid = append(invalid, line.Line)
+			}
+
+			if !slices.Equal(invalid, test.wantInvalid) {
+				t.Errorf("invalid lines = %v, want %v", invalid, test.wantInvalid)
+			}
+		})
+	}
+
+	if _, err := Parse(strings.NewReader("4 Lightning Bolt"), Format(99)); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Parse() with an unknown format error = %v, want ErrUnknownFormat", err)
+	}
+}
@@ -0,0 +1,414 @@
+package legality
+
+import (
+	"errors"
+	cardModel "github.com/stevezaluk/mtgjson-models/card"
+	deckModel "github.com/stevezaluk/mtgjson-models/deck"
+	sdkErrors "github.com/stevezaluk/mtgjson-models/errors"
+	"github.com/stevezaluk/mtgjson-sdk-client/card"
+	"math"
+	"regexp"
+	"slices"
+)
+
+/*
+ErrUnknownFormat - Returned when Validate is called with a Format that it does not support
+*/
+var ErrUnknownFormat = errors.New("legality: unknown format")
+
+/*
+ErrDeckSize - Recorded when the main deck has fewer cards than the format requires, or in a commander format, when
+the main deck and commanders do not add up to the exact size of the deck
+*/
+var ErrDeckSize = errors.New("legality: wrong number of cards in the deck")
+
+/*
+ErrSideboardSize - Recorded when the sideboard has more cards than the format allows. In a commander format, the
+sideboard may only hold a single companion
+*/
+var ErrSideboardSize = errors.New("legality: too many cards in the sideboard")
+
+/*
+ErrCommanderCount - Recorded when a commander format deck does not have one or two commanders, or when a deck in any
+other format has cards in its commander zone
+*/
+var ErrCommanderCount = errors.New("legality: wrong number of commanders")
+
+/*
+ErrTooManyCopies - Recorded when a card appears more times than the format allows
+*/
+var ErrTooManyCopies = errors.New("legality: too many copies of a card")
+
+/*
+ErrBanned - Recorded when a card is banned in the format
+*/
+var ErrBanned = errors.New("legality: card is banned")
+
+/*
+ErrNotLegal - Recorded when a card is not legal in the format, for example because its set is not part of it
+*/
+var ErrNotLegal = errors.New("legality: card is not legal")
+
+/*
+ErrNotCommander - Recorded when a card in the commander zone cannot be a commander in the format
+*/
+var ErrNotCommander = errors.New("legality: card cannot be a commander")
+
+/*
+ErrColorIdentity - Recorded when the color identity of a card is not within the color identity of the commanders
+*/
+var ErrColorIdentity = errors.New("legality: card is outside the color identity of the commander")
+
+/*
+Format - A format that Validate can check a deck against
+*/
+type Format int
+
+const (
+	// FormatStandard - Standard: at least 60 cards, a sideboard of up to 15 and up to 4 copies of a card
+	FormatStandard Format = iota
+
+	// FormatPioneer - Pioneer, with the same deck construction rules as Standard
+	FormatPioneer
+
+	// FormatModern - Modern, with the same deck construction rules as Standard
+	FormatModern
+
+	// FormatLegacy - Legacy, with the same deck construction rules as Standard
+	FormatLegacy
+
+	// FormatVintage - Vintage, with the same deck construction rules as Standard. Restricted cards are limited to a
+	// single copy
+	FormatVintage
+
+	// FormatPauper - Pauper, with the same deck construction rules as Standard
+	FormatPauper
+
+	// FormatCommander - Commander: exactly 100 cards including the commanders, no sideboard, a single copy of a card
+	// and every card within the color identity of the commanders
+	FormatCommander
+
+	// FormatBrawl - Brawl, which was called Historic Brawl until 2023 and is the variant that the brawl legalities of
+	// MTGJSON cover since: the rules of Commander with a pool of Arena cards. The 60 card Standard Brawl, which MTGJSON
+	// keys as standardbrawl, is not supported as the card models do not carry its legalities
+	FormatBrawl
+)
+
+/*
+rules - The deck construction rules of a format
+*/
+type rules struct {
+	// name - The name of the format as it appears in the legalities of a card
+	name string
+
+	// deckSize - The minimum number of cards in the main deck, or in a commander format, the exact number of cards in
+	// the main deck and commander zone together
+	deckSize int
+
+	// sideboardSize - The maximum number of cards in the sideboard
+	sideboardSize int
+
+	// copies - The maximum number of copies of a card, other than basic lands
+	copies int
+
+	// companion - True if the sideboard may hold a single companion beyond sideboardSize, for formats without a
+	// sideboard
+	companion bool
+
+	// commander - Returns true if the card can be a commander in the format. Nil for formats without a commander
+	commander func(card *cardModel.CardSet) bool
+}
+
+/*
+formats - The rules of each Format that Validate supports
+*/
+var formats = map[Format]rules{
+	FormatStandard: {name: "standard", deckSize: 60, sideboardSize: 15, copies: 4},
+	FormatPioneer:  {name: "pioneer", deckSize: 60, sideboardSize: 15, copies: 4},
+	FormatModern:   {name: "modern", deckSize: 60, sideboardSize: 15, copies: 4},
+	FormatLegacy:   {name: "legacy", deckSize: 60, sideboardSize: 15, copies: 4},
+	FormatVintage:  {name: "vintage", deckSize: 60, sideboardSize: 15, copies: 4},
+	FormatPauper:   {name: "pauper", deckSize: 60, sideboardSize: 15, copies: 4},
+	FormatCommander: {name: "commander", deckSize: 100, copies: 1, companion: true, commander: func(card *cardModel.CardSet) bool {
+		return card.GetLeadershipSkills().GetCommander()
+	}},
+	FormatBrawl: {name: "brawl", deckSize: 100, copies: 1, companion: true, commander: func(card *cardModel.CardSet) bool {
+		return card.GetLeadershipSkills().GetBrawl()
+	}},
+}
+
+/*
+String - Returns the name of the format as it appears in the legalities of a card
+*/
+func (format Format) String() string {
+	rules, ok := formats[format]
+	if !ok {
+		return "unknown"
+	}
+
+	return rules.name
+}
+
+/*
+Violation - A rule of the format that a deck breaks
+*/
+type Violation struct {
+	// UUID - The MTGJSONv4 ID of the card that breaks the rule, or empty if the rule applies to the whole deck. If
+	// several printings of the card are in the deck, this is the first of them
+	UUID string
+
+	// Name - The name of the card that breaks the rule, or empty if the rule applies to the whole deck
+	Name string
+
+	// Err - The rule that is broken: one of the errors of this package, or ErrNoCard if a content ID of the deck is
+	// not among the cards passed to Validate
+	Err error
+
+	// Count - The number of cards found, for ErrDeckSize, ErrSideboardSize, ErrCommanderCount, ErrTooManyCopies and
+	// ErrNoCard
+	Count int
+
+	// Limit - The number of cards allowed, for ErrDeckSize, ErrSideboardSize, ErrCommanderCount and
+	// ErrTooManyCopies. For ErrDeckSize this is the minimum size of the main deck, or the exact size of a deck in a
+	// commander format
+	Limit int
+}
+
+/*
+copyLimitPattern - Matches the rules text of cards that override the limit on copies, such as Relentless Rats and
+Seven Dwarves
+*/
+var copyLimitPattern = regexp.MustCompile(`A deck can have (?:any number of|up to (\w+)) cards named`)
+
+/*
+copyLimits - The limits on copies that appear in rules text matched by copyLimitPattern
+*/
+var copyLimits = map[string]int{"seven": 7, "nine": 9}
+
+/*
+companionPattern - Matches the rules text of a card with the companion ability
+*/
+var companionPattern = regexp.MustCompile(`(?m)^Companion —`)
+
+/*
+group - The copies of a card in a deck, across every board
+*/
+type group struct {
+	// card - The first printing of the card in the deck
+	card *cardModel.CardSet
+
+	// count - The number of copies of the card in the deck
+	count int
+
+	// commander - True if the card is in the commander zone
+	commander bool
+}
+
+/*
+Validate - Check the deck passed in the parameter against the rules of a format. The cards are the resolved content
+IDs of the deck, usually fetched with CardAPI.GetCards, and are matched to the deck by MTGJSONv4 ID so each printing
+only needs to be passed once. Cards are checked against their legalities, and copies of a card are counted by name
+across the main deck, sideboard and commander zone, so different printings of a card count towards the same limit.
+Basic lands and cards whose rules text allows any number of copies are exempt from the limit. In a commander format,
+the sideboard may hold a single companion, such as the Companion section of an Arena export. Whether a pair of
+commanders are allowed to be partners is not checked. Returns the violations of the whole deck first, followed by
+those of each card in the order it first appears in the deck, or nil if the deck is legal. An error is only returned
+if the format is unknown
+*/
+func Validate(deck *deckModel.Deck, cards []*cardModel.CardSet, format Format) ([]Violation, error) {
+	rules, ok := formats[format]
+	if !ok {
+		return nil, ErrUnknownFormat
+	}
+
+	index := make(map[string]*cardModel.CardSet, len(cards))
+	for _, card := range cards {
+		index[card.GetIdentifiers().GetMtgjsonV4Id()] = card
+	}
+
+	contentIds := deck.GetContentIds()
+	violations := checkSizes(contentIds, index, rules)
+
+	var groups []*group
+	byName := make(map[string]*group)
+	missing := make(map[string]*Violation)
+	var missingOrder []string
+
+	boards := [][]string{contentIds.GetCommander(), contentIds.GetMainBoard(), contentIds.GetSideBoard()}
+	for position, board := range boards {
+		for _, uuid := range board {
+			card, ok := index[uuid]
+			if !ok {
+				if _, seen := missing[uuid]; !seen {
+					missing[uuid] = &Violation{UUID: uuid, Err: sdkErrors.ErrNoCard}
+					missingOrder = append(missingOrder, uuid)
+				}
+
+				missing[uuid].Count++
+				continue
+			}
+
+			entry, ok := byName[card.GetName()]
+			if !ok {
+				entry = &group{card: card}
+				byName[card.GetName()] = entry
+				groups = append(groups, entry)
+			}
+
+			entry.count++
+			entry.commander = entry.commander || position == 0
+		}
+	}
+
+	for _, uuid := range missingOrder {
+		violations = append(violations, *missing[uuid])
+	}
+
+	identity, checkIdentity := commanderIdentity(groups, rules)
+
+	for _, entry := range groups {
+		violations = append(violations, checkCard(entry, rules, identity, checkIdentity)...)
+	}
+
+	return violations, nil
+}
+
+/*
+checkSizes - Returns the violations of the size of each board of the deck
+*/
+func checkSizes(contentIds *deckModel.DeckContentIds, index map[string]*cardModel.CardSet, rules rules) []Violation {
+	var violations []Violation
+
+	mainBoard := len(contentIds.GetMainBoard())
+	commanders := len(contentIds.GetCommander())
+
+	if rules.commander == nil {
+		if mainBoard < rules.deckSize {
+			violations = append(violations, Violation{Err: ErrDeckSize, Count: mainBoard, Limit: rules.deckSize})
+		}
+
+		if commanders != 0 {
+			violations = append(violations, Violation{Err: ErrCommanderCount, Count: commanders})
+		}
+	} else {
+		if mainBoard+commanders != rules.deckSize {
+			violations = append(violations, Violation{Err: ErrDeckSize, Count: mainBoard + commanders, Limit: rules.deckSize})
+		}
+
+		if commanders == 0 || commanders > 2 {
+			violations = append(violations, Violation{Err: ErrCommanderCount, Count: commanders, Limit: 2})
+		}
+	}
+
+	sideboard := len(contentIds.GetSideBoard())
+	if rules.companion && sideboard == 1 && companionPattern.MatchString(index[contentIds.GetSideBoard()[0]].GetText()) {
+		sideboard = 0
+	}
+
+	if sideboard > rules.sideboardSize {
+		violations = append(violations, Violation{Err: ErrSideboardSize, Count: sideboard, Limit: rules.sideboardSize})
+	}
+
+	return violations
+}
+
+/*
+commanderIdentity - Returns the combined color identity of the commanders of the deck, and whether the color identity
+of the other cards should be checked against it. It is only checked in commander formats, once a commander is known
+*/
+func commanderIdentity(groups []*group, rules rules) ([]string, bool) {
+	if rules.commander == nil {
+		return nil, false
+	}
+
+	var identity []string
+	found := false
+
+	for _, entry := range groups {
+		if !entry.commander {
+			continue
+		}
+
+		identity = append(identity, entry.card.GetColorIdentity()...)
+		found = true
+	}
+
+	return identity, found
+}
+
+/*
+checkCard - Returns the violations of the card passed in the parameter
+*/
+func checkCard(entry *group, rules rules, identity []string, checkIdentity bool) []Violation {
+	var violations []Violation
+
+	violation := func(err error) Violation {
+		return Violation{UUID: entry.card.GetIdentifiers().GetMtgjsonV4Id(), Name: entry.card.GetName(), Err: err}
+	}
+
+	limit := copyLimit(entry.card, rules)
+
+	switch card.Legality(entry.card, rules.name) {
+	case "Legal":
+	case "Restricted":
+		limit = min(limit, 1)
+	case "Banned":
+		violations = append(violations, violation(ErrBanned))
+	default:
+		violations = append(violations, violation(ErrNotLegal))
+	}
+
+	if entry.count > limit {
+		tooMany := violation(ErrTooManyCopies)
+		tooMany.Count = entry.count
+		tooMany.Limit = limit
+		violations = append(violations, tooMany)
+	}
+
+	if entry.commander && rules.commander != nil && !rules.commander(entry.card) {
+		violations = append(violations, violation(ErrNotCommander))
+	}
+
+	if checkIdentity && !entry.commander && !within(entry.card.GetColorIdentity(), identity) {
+		violations = append(violations, violation(ErrColorIdentity))
+	}
+
+	return violations
+}
+
+/*
+copyLimit - Returns the maximum number of copies of the card passed in the parameter that the format allows
+*/
+func copyLimit(entry *cardModel.CardSet, rules rules) int {
+	if slices.Contains(entry.GetSupertypes(), "Basic") && slices.Contains(entry.GetTypes(), "Land") {
+		return math.MaxInt
+	}
+
+	match := copyLimitPattern.FindStringSubmatch(entry.GetText())
+	if match == nil {
+		return rules.copies
+	}
+
+	if match[1] == "" {
+		return math.MaxInt
+	}
+
+	if limit, ok := copyLimits[match[1]]; ok {
+		return limit
+	}
+
+	return rules.copies
+}
+
+/*
+within - Returns true if every color of the identity passed in the parameter is one of the allowed colors
+*/
+func within(identity []string, allowed []string) bool {
+	for _, color := range identity {
+		if !slices.Contains(allowed, color) {
+			return false
+		}
+	}
+
+	return true
+}
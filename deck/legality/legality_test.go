@@ -0,0 +1,225 @@
+package legality_test
+
+import (
+	"errors"
+	"fmt"
+	cardModel "github.com/stevezaluk/mtgjson-models/card"
+	deckModel "github.com/stevezaluk/mtgjson-models/deck"
+	sdkErrors "github.com/stevezaluk/mtgjson-models/errors"
+	"github.com/stevezaluk/mtgjson-sdk-client/deck/legality"
+	"slices"
+	"testing"
+)
+
+/*
+legal - The legalities of a card that is legal in every format
+*/
+func legal() *cardModel.Legalities {
+	return &cardModel.Legalities{
+		Standard:  "Legal",
+		Pioneer:   "Legal",
+		Modern:    "Legal",
+		Legacy:    "Legal",
+		Vintage:   "Legal",
+		Pauper:    "Legal",
+		Commander: "Legal",
+		Brawl:     "Legal",
+	}
+}
+
+/*
+fixtureCards - The cards the decks of the tests are built from, keyed by MTGJSONv4 ID
+*/
+var fixtureCards = map[string]*cardModel.CardSet{
+	"bolt":     {Name: "Lightning Bolt", ColorIdentity: []string{"R"}, Legalities: legal()},
+	"bolt-m10": {Name: "Lightning Bolt", ColorIdentity: []string{"R"}, Legalities: legal()},
+	"mountain": {Name: "Mountain", Supertypes: []string{"Basic"}, Types: []string{"Land"}, ColorIdentity: []string{"R"}, Legalities: legal()},
+	"rats":     {Name: "Relentless Rats", Text: "A deck can have any number of cards named Relentless Rats.", ColorIdentity: []string{"B"}, Legalities: legal()},
+	"dwarves":  {Name: "Seven Dwarves", Text: "A deck can have up to seven cards named Seven Dwarves.", ColorIdentity: []string{"R"}, Legalities: legal()},
+	"counter":  {Name: "Counterspell", ColorIdentity: []string{"U"}, Legalities: legal()},
+	"krenko": {
+		Name:             "Krenko, Mob Boss",
+		ColorIdentity:    []string{"R"},
+		Legalities:       legal(),
+		LeadershipSkills: &cardModel.LeadershipSkills{Commander: true, Brawl: true},
+	},
+	"lurrus": {
+		Name:          "Lurrus of the Dream-Den",
+		Text:          "Companion — Each permanent card in your starting deck has mana value 2 or less.\nLifelink",
+		ColorIdentity: []string{"R"},
+		Legalities:    legal(),
+	},
+	"ring": {
+		Name:       "Sol Ring",
+		Legalities: &cardModel.Legalities{Vintage: "Restricted", Legacy: "Banned", Commander: "Legal"},
+	},
+}
+
+/*
+repeat - Returns the MTGJSONv4 ID passed in the parameter count times
+*/
+func repeat(uuid string, count int) []string {
+	return slices.Repeat([]string{uuid}, count)
+}
+
+/*
+newDeck - Returns a deck with the boards passed in the parameters
+*/
+func newDeck(mainBoard []string, sideBoard []string, commander []string) *deckModel.Deck {
+	return &deckModel.Deck{
+		ContentIds: &deckModel.DeckContentIds{MainBoard: mainBoard, SideBoard: sideBoard, Commander: commander},
+	}
+}
+
+/*
+summarize - Returns each violation as "name: error count/limit", for comparing in tests
+*/
+func summarize(violations []legality.Violation) []string {
+	var summary []string
+	for _, violation := range violations {
+		summary = append(summary, fmt.Sprintf("%s: %v %d/%d", violation.Name, violation.Err, violation.Count, violation.Limit))
+	}
+
+	return summary
+}
+
+func TestValidate(t *testing.T) {
+	cards := make([]*cardModel.CardSet, 0, len(fixtureCards))
+	for uuid, card := range fixtureCards {
+		card.Identifiers = &cardModel.CardIdentifiers{MtgjsonV4Id: uuid}
+		cards = append(cards, card)
+	}
+
+	tests := []struct {
+		name   string
+		deck   *deckModel.Deck
+		format legality.Format
+		want   []string
+	}{
+		{
+			name:   "legal standard deck",
+			deck:   newDeck(append(repeat("bolt", 4), repeat("mountain", 56)...), repeat("counter", 4), nil),
+			format: legality.FormatStandard,
+		},
+		{
+			name:   "deck too small and sideboard too large",
+			deck:   newDeck(repeat("mountain", 59), repeat("mountain", 16), nil),
+			format: legality.FormatModern,
+			want: []string{
+				fmt.Sprintf(": %v 59/60", legality.ErrDeckSize),
+				fmt.Sprintf(": %v 16/15", legality.ErrSideboardSize),
+			},
+		},
+		{
+			name:   "copies counted by name across printings and boards",
+			deck:   newDeck(append(append(repeat("bolt", 3), repeat("bolt-m10", 1)...), repeat("mountain", 56)...), repeat("bolt", 1), nil),
+			format: legality.FormatPioneer,
+			want:   []string{fmt.Sprintf("Lightning Bolt: %v 5/4", legality.ErrTooManyCopies)},
+		},
+		{
+			name:   "rules text overrides the copy limit",
+			deck:   newDeck(append(append(repeat("rats", 30), repeat("dwarves", 8)...), repeat("mountain", 22)...), nil, nil),
+			format: legality.FormatLegacy,
+			want:   []string{fmt.Sprintf("Seven Dwarves: %v 8/7", legality.ErrTooManyCopies)},
+		},
+		{
+			name:   "restricted card",
+			deck:   newDeck(append(repeat("ring", 2), repeat("mountain", 58)...), nil, nil),
+			format: legality.FormatVintage,
+			want:   []string{fmt.Sprintf("Sol Ring: %v 2/1", legality.ErrTooManyCopies)},
+		},
+		{
+			name:   "banned card",
+			deck:   newDeck(append(repeat("ring", 1), repeat("mountain", 59)...), nil, nil),
+			format: legality.FormatLegacy,
+			want:   []string{fmt.Sprintf("Sol Ring: %v 0/0", legality.ErrBanned)},
+		},
+		{
+			name:   "card not legal in the format",
+			deck:   newDeck(append(repeat("ring", 1), repeat("mountain", 59)...), nil, nil),
+			format: legality.FormatPauper,
+			want:   []string{fmt.Sprintf("Sol Ring: %v 0/0", legality.ErrNotLegal)},
+		},
+		{
+			name:   "commander zone outside a commander format",
+			deck:   newDeck(repeat("mountain", 60), nil, repeat("krenko", 1)),
+			format: legality.FormatStandard,
+			want:   []string{fmt.Sprintf(": %v 1/0", legality.ErrCommanderCount)},
+		},
+		{
+			name:   "missing card",
+			deck:   newDeck(append(repeat("unknown", 2), repeat("mountain", 58)...), nil, nil),
+			format: legality.FormatStandard,
+			want:   []string{fmt.Sprintf(": %v 2/0", sdkErrors.ErrNoCard)},
+		},
+		{
+			name:   "legal commander deck",
+			deck:   newDeck(append(repeat("bolt", 1), repeat("mountain", 98)...), nil, repeat("krenko", 1)),
+			format: legality.FormatCommander,
+		},
+		{
+			name:   "commander deck of the wrong size without a commander",
+			deck:   newDeck(repeat("mountain", 99), nil, nil),
+			format: legality.FormatCommander,
+			want: []string{
+				fmt.Sprintf(": %v 99/100", legality.ErrDeckSize),
+				fmt.Sprintf(": %v 0/2", legality.ErrCommanderCount),
+			},
+		},
+		{
+			name:   "singleton, commander and color identity",
+			deck:   newDeck(append(append(repeat("bolt", 2), repeat("counter", 1)...), repeat("mountain", 96)...), nil, repeat("bolt-m10", 1)),
+			format: legality.FormatCommander,
+			want: []string{
+				fmt.Sprintf("Lightning Bolt: %v 3/1", legality.ErrTooManyCopies),
+				fmt.Sprintf("Lightning Bolt: %v 0/0", legality.ErrNotCommander),
+				fmt.Sprintf("Counterspell: %v 0/0", legality.ErrColorIdentity),
+			},
+		},
+		{
+			name:   "companion in the sideboard of a commander deck",
+			deck:   newDeck(repeat("mountain", 99), repeat("lurrus", 1), repeat("krenko", 1)),
+			format: legality.FormatCommander,
+		},
+		{
+			name:   "sideboard of a commander deck that is not a companion",
+			deck:   newDeck(repeat("mountain", 99), repeat("bolt", 1), repeat("krenko", 1)),
+			format: legality.FormatCommander,
+			want:   []string{fmt.Sprintf(": %v 1/0", legality.ErrSideboardSize)},
+		},
+		{
+			name:   "companion and another card in the sideboard of a brawl deck",
+			deck:   newDeck(repeat("mountain", 99), append(repeat("lurrus", 1), "bolt"), repeat("krenko", 1)),
+			format: legality.FormatBrawl,
+			want:   []string{fmt.Sprintf(": %v 2/0", legality.ErrSideboardSize)},
+		},
+		{
+			name:   "legal brawl deck of 100 cards",
+			deck:   newDeck(repeat("mountain", 99), repeat("lurrus", 1), repeat("krenko", 1)),
+			format: legality.FormatBrawl,
+		},
+		{
+			name:   "standard brawl deck of 60 cards",
+			deck:   newDeck(repeat("mountain", 59), nil, repeat("krenko", 1)),
+			format: legality.FormatBrawl,
+			want:   []string{fmt.Sprintf(": %v 60/100", legality.ErrDeckSize)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			violations, err := legality.Validate(test.deck, cards, test.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := summarize(violations); !slices.Equal(got, test.want) {
+				t.Errorf("violations = %q, want %q", got, test.want)
+			}
+		})
+	}
+
+	if _, err := legality.Validate(newDeck(nil, nil, nil), cards, legality.Format(99)); !errors.Is(err, legality.ErrUnknownFormat) {
+		t.Errorf("Validate() with an unknown format error = %v, want ErrUnknownFormat", err)
+	}
+}
@@ -0,0 +1,157 @@
+package stats
+
+import (
+	"encoding/json"
+	cardModel "github.com/stevezaluk/mtgjson-models/card"
+	deckModel "github.com/stevezaluk/mtgjson-models/deck"
+	"io"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+/*
+colors - The colors of Magic in WUBRG order, which color identities are sorted in
+*/
+const colors = "WUBRG"
+
+/*
+manaSymbol - Matches a single mana symbol of a mana cost, such as {2}, {G} or {W/U}, capturing its contents
+*/
+var manaSymbol = regexp.MustCompile(`\{([^}]+)\}`)
+
+/*
+Zone - The statistics of a single zone of a deck
+*/
+type Zone struct {
+	// Cards - The number of cards in the zone, counting each copy
+	Cards int `json:"cards"`
+
+	// Lands - The number of lands in the zone
+	Lands int `json:"lands"`
+
+	// AverageManaValue - The average mana value of the cards in the zone that are not lands, or 0 if there are none
+	AverageManaValue float64 `json:"averageManaValue"`
+
+	// Curve - The number of cards that are not lands at each mana value. Fractional mana values are rounded down
+	Curve map[int]int `json:"curve"`
+
+	// Pips - The number of colored and colorless mana symbols in the mana costs of the cards, keyed by W, U, B, R, G
+	// and C. Hybrid symbols count towards each of their colors, and Phyrexian symbols towards their color
+	Pips map[string]int `json:"pips"`
+
+	// Types - The number of cards of each card type. A card with several types, such as an artifact creature,
+	// counts towards each of them
+	Types map[string]int `json:"types"`
+
+	// ColorIdentity - The combined color identity of the cards in the zone, in WUBRG order
+	ColorIdentity []string `json:"colorIdentity"`
+}
+
+/*
+Stats - The statistics of a deck, computed separately for each of its zones
+*/
+type Stats struct {
+	// MainBoard - The statistics of the main deck
+	MainBoard Zone `json:"mainBoard"`
+
+	// SideBoard - The statistics of the sideboard
+	SideBoard Zone `json:"sideBoard"`
+
+	// Commander - The statistics of the commander zone. Its color identity is the color identity of the deck in
+	// commander formats
+	Commander Zone `json:"commander"`
+}
+
+/*
+Compute - Compute the statistics of the deck contents passed in the parameter, as returned by
+DeckAPI.GetDeckContents. Each copy of a card is counted, as the contents hold one model per copy
+*/
+func Compute(contents *deckModel.DeckContents) *Stats {
+	return &Stats{
+		MainBoard: computeZone(contents.GetMainBoard()),
+		SideBoard: computeZone(contents.GetSideBoard()),
+		Commander: computeZone(contents.GetCommander()),
+	}
+}
+
+/*
+WriteJSON - Write the statistics to the writer as an indented JSON object
+*/
+func (stats *Stats) WriteJSON(writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(stats)
+}
+
+/*
+computeZone - Compute the statistics of the cards of a single zone
+*/
+func computeZone(cards []*cardModel.CardSet) Zone {
+	zone := Zone{
+		Cards:         len(cards),
+		Curve:         make(map[int]int),
+		Pips:          make(map[string]int),
+		Types:         make(map[string]int),
+		ColorIdentity: []string{},
+	}
+
+	spells := 0
+	total := 0.0
+
+	for _, card := range cards {
+		for _, cardType := range card.GetTypes() {
+			zone.Types[cardType]++
+		}
+
+		for color, count := range pips(card.GetManaCost()) {
+			zone.Pips[color] += count
+		}
+
+		for _, color := range card.GetColorIdentity() {
+			if !slices.Contains(zone.ColorIdentity, color) {
+				zone.ColorIdentity = append(zone.ColorIdentity, color)
+			}
+		}
+
+		if slices.Contains(card.GetTypes(), "Land") {
+			zone.Lands++
+			continue
+		}
+
+		manaValue := float64(card.GetManaValue())
+		zone.Curve[int(math.Floor(manaValue))]++
+		total += manaValue
+		spells++
+	}
+
+	if spells != 0 {
+		zone.AverageManaValue = total / float64(spells)
+	}
+
+	slices.SortFunc(zone.ColorIdentity, func(a string, b string) int {
+		return strings.Index(colors, a) - strings.Index(colors, b)
+	})
+
+	return zone
+}
+
+/*
+pips - Returns the number of colored and colorless mana symbols in the mana cost passed in the parameter, keyed by
+color. Generic and variable symbols such as {2} and {X} are not counted
+*/
+func pips(manaCost string) map[string]int {
+	counts := make(map[string]int)
+
+	for _, match := range manaSymbol.FindAllStringSubmatch(manaCost, -1) {
+		for _, part := range strings.Split(match[1], "/") {
+			if part == "C" || (len(part) == 1 && strings.Contains(colors, part)) {
+				counts[part]++
+			}
+		}
+	}
+
+	return counts
+}
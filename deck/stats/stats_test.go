@@ -0,0 +1,132 @@
+package stats_test
+
+import (
+	"bytes"
+	"encoding/json"
+	cardModel "github.com/stevezaluk/mtgjson-models/card"
+	deckModel "github.com/stevezaluk/mtgjson-models/deck"
+	"github.com/stevezaluk/mtgjson-sdk-client/deck/stats"
+	"reflect"
+	"testing"
+)
+
+/*
+Fixture cards - One model per card, repeated in a zone once per copy
+*/
+var (
+	bolt     = &cardModel.CardSet{Name: "Lightning Bolt", ManaCost: "{R}", ManaValue: 1, Types: []string{"Instant"}, ColorIdentity: []string{"R"}}
+	mountain = &cardModel.CardSet{Name: "Mountain", Types: []string{"Land"}, ColorIdentity: []string{"R"}}
+	helix    = &cardModel.CardSet{Name: "Lightning Helix", ManaCost: "{R}{W}", ManaValue: 2, Types: []string{"Instant"}, ColorIdentity: []string{"W", "R"}}
+	golem    = &cardModel.CardSet{Name: "Dross Golem", ManaCost: "{5}", ManaValue: 5, Types: []string{"Artifact", "Creature"}}
+	hybrid   = &cardModel.CardSet{Name: "Boros Guildmage", ManaCost: "{R/W}{R/W}", ManaValue: 2, Types: []string{"Creature"}, ColorIdentity: []string{"R", "W"}}
+	probe    = &cardModel.CardSet{Name: "Gitaxian Probe", ManaCost: "{U/P}", ManaValue: 1, Types: []string{"Sorcery"}, ColorIdentity: []string{"U"}}
+	eldrazi  = &cardModel.CardSet{Name: "Thought-Knot Seer", ManaCost: "{3}{C}", ManaValue: 4, Types: []string{"Creature"}}
+	fireball = &cardModel.CardSet{Name: "Fireball", ManaCost: "{X}{R}", ManaValue: 1, Types: []string{"Sorcery"}, ColorIdentity: []string{"R"}}
+	half     = &cardModel.CardSet{Name: "Little Girl", ManaCost: "{HW}", ManaValue: 0.5, Types: []string{"Creature"}, ColorIdentity: []string{"W"}}
+)
+
+func TestCompute(t *testing.T) {
+	tests := []struct {
+		name  string
+		cards []*cardModel.CardSet
+		want  stats.Zone
+	}{
+		{
+			name: "empty zone",
+			want: stats.Zone{Curve: map[int]int{}, Pips: map[string]int{}, Types: map[string]int{}, ColorIdentity: []string{}},
+		},
+		{
+			name:  "lands are left out of the curve and average",
+			cards: []*cardModel.CardSet{bolt, bolt, helix, mountain, mountain},
+			want: stats.Zone{
+				Cards:            5,
+				Lands:            2,
+				AverageManaValue: 4.0 / 3,
+				Curve:            map[int]int{1: 2, 2: 1},
+				Pips:             map[string]int{"R": 3, "W": 1},
+				Types:            map[string]int{"Instant": 3, "Land": 2},
+				ColorIdentity:    []string{"W", "R"},
+			},
+		},
+		{
+			name:  "only lands",
+			cards: []*cardModel.CardSet{mountain},
+			want: stats.Zone{
+				Cards:         1,
+				Lands:         1,
+				Curve:         map[int]int{},
+				Pips:          map[string]int{},
+				Types:         map[string]int{"Land": 1},
+				ColorIdentity: []string{"R"},
+			},
+		},
+		{
+			name:  "hybrid, phyrexian, colorless and generic symbols",
+			cards: []*cardModel.CardSet{hybrid, probe, eldrazi, golem, fireball},
+			want: stats.Zone{
+				Cards:            5,
+				AverageManaValue: 13.0 / 5,
+				Curve:            map[int]int{1: 2, 2: 1, 4: 1, 5: 1},
+				Pips:             map[string]int{"R": 3, "W": 2, "U": 1, "C": 1},
+				Types:            map[string]int{"Creature": 3, "Artifact": 1, "Sorcery": 2},
+				ColorIdentity:    []string{"W", "U", "R"},
+			},
+		},
+		{
+			name:  "fractional mana values are rounded down",
+			cards: []*cardModel.CardSet{half},
+			want: stats.Zone{
+				Cards:            1,
+				AverageManaValue: 0.5,
+				Curve:            map[int]int{0: 1},
+				Pips:             map[string]int{},
+				Types:            map[string]int{"Creature": 1},
+				ColorIdentity:    []string{"W"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := stats.Compute(&deckModel.DeckContents{SideBoard: test.cards})
+
+			if !reflect.DeepEqual(got.SideBoard, test.want) {
+				t.Errorf("sideboard = %+v, want %+v", got.SideBoard, test.want)
+			}
+
+			if got.MainBoard.Cards != 0 || got.Commander.Cards != 0 {
+				t.Errorf("main board = %+v, commander = %+v, want each zone computed separately", got.MainBoard, got.Commander)
+			}
+		})
+	}
+}
+
+func TestComputeZones(t *testing.T) {
+	got := stats.Compute(&deckModel.DeckContents{
+		MainBoard: []*cardModel.CardSet{bolt, mountain},
+		SideBoard: []*cardModel.CardSet{helix},
+		Commander: []*cardModel.CardSet{hybrid},
+	})
+
+	if got.MainBoard.Cards != 2 || got.SideBoard.Cards != 1 || got.Commander.Cards != 1 {
+		t.Errorf("cards = %d/%d/%d, want 2/1/1", got.MainBoard.Cards, got.SideBoard.Cards, got.Commander.Cards)
+	}
+
+	if want := []string{"W", "R"}; !reflect.DeepEqual(got.Commander.ColorIdentity, want) {
+		t.Errorf("commander color identity = %v, want %v", got.Commander.ColorIdentity, want)
+	}
+
+	var buffer bytes.Buffer
+	if err := got.WriteJSON(&buffer); err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded stats.Stats
+	if err := json.Unmarshal(buffer.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(&decoded, got) {
+		t.Errorf("WriteJSON() round trip = %+v, want %+v", decoded, *got)
+	}
+}
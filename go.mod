@@ -9,6 +9,8 @@ require (
 	github.com/go-resty/resty/v2 v2.16.2
 	github.com/mitchellh/go-homedir v1.1.0
 	github.com/spf13/viper v1.19.0
+	github.com/ulikunitz/xz v0.5.17
+	google.golang.org/protobuf v1.35.2
 )
 
 require (
@@ -30,7 +32,6 @@ require (
 	golang.org/x/net v0.27.0 // indirect
 	golang.org/x/sys v0.27.0 // indirect
 	golang.org/x/text v0.16.0 // indirect
-	google.golang.org/protobuf v1.35.2 // indirect
 	gopkg.in/ini.v1 v1.67.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,163 @@
+package offline
+
+import (
+	"context"
+	apiModels "github.com/stevezaluk/mtgjson-models/api"
+	cardModel "github.com/stevezaluk/mtgjson-models/card"
+	sdkErrors "github.com/stevezaluk/mtgjson-models/errors"
+	"github.com/stevezaluk/mtgjson-sdk-client/api"
+	"github.com/stevezaluk/mtgjson-sdk-client/card"
+	"github.com/stevezaluk/mtgjson-sdk-client/client"
+	"iter"
+)
+
+/*
+CardAPI - A read-only implementation of api.CardService that serves cards from a Store. The owner parameters are
+ignored, as the MTGJSON data files have no concept of ownership. The returned models are shared with the store and
+must not be modified
+*/
+type CardAPI struct {
+	// store - The store the cards are read from
+	store *Store
+}
+
+var _ api.CardService = (*CardAPI)(nil)
+
+/*
+GetCard - Returns the card under the MTGJSONv4 ID passed in the parameter. Returns ErrNoCard if it was not loaded
+*/
+func (api *CardAPI) GetCard(ctx context.Context, uuid string, owner string) (*cardModel.CardSet, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	api.store.mutex.RLock()
+	defer api.store.mutex.RUnlock()
+
+	card, ok := api.store.cards[uuid]
+	if !ok {
+		return nil, sdkErrors.ErrNoCard
+	}
+
+	return card, nil
+}
+
+/*
+GetCards - Returns the loaded cards under the MTGJSONv4 IDs passed in the parameter, in the order that their IDs first
+appear. IDs that were not loaded are returned in a map with ErrNoCard rather than failing the batch
+*/
+func (api *CardAPI) GetCards(ctx context.Context, uuids []string, owner string) ([]*cardModel.CardSet, map[string]error, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	api.store.mutex.RLock()
+	defer api.store.mutex.RUnlock()
+
+	cards := make([]*cardModel.CardSet, 0, len(uuids))
+	missing := make(map[string]error)
+	seen := make(map[string]bool, len(uuids))
+
+	for _, uuid := range uuids {
+		if seen[uuid] {
+			continue
+		}
+
+		seen[uuid] = true
+
+		card, ok := api.store.cards[uuid]
+		if !ok {
+			missing[uuid] = sdkErrors.ErrNoCard
+			continue
+		}
+
+		cards = append(cards, card)
+	}
+
+	return cards, missing, nil
+}
+
+/*
+IndexCards - Returns a page of the loaded cards, ordered by MTGJSONv4 ID. A limit of 0 or less returns every card after
+the offset. Returns ErrNoCards if the page is empty
+*/
+func (api *CardAPI) IndexCards(ctx context.Context, limit int, offset int) (*[]*cardModel.CardSet, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	api.store.mutex.RLock()
+	defer api.store.mutex.RUnlock()
+
+	uuids := page(api.store.sortedCardIds(), limit, offset)
+	if len(uuids) == 0 {
+		return nil, sdkErrors.ErrNoCards
+	}
+
+	cards := make([]*cardModel.CardSet, 0, len(uuids))
+	for _, uuid := range uuids {
+		cards = append(cards, api.store.cards[uuid])
+	}
+
+	return &cards, nil
+}
+
+/*
+IterCards - Returns an iterator over every loaded card, ordered by MTGJSONv4 ID, see client.Paginate
+*/
+func (api *CardAPI) IterCards(ctx context.Context, pageSize int) iter.Seq2[*cardModel.CardSet, error] {
+	return client.Paginate(ctx, pageSize, sdkErrors.ErrNoCards, api.IndexCards)
+}
+
+/*
+StreamCards - Returns an iterator over a page of the loaded cards, selected in the same way as IndexCards. The cards
+are already held in memory, so this only exists to satisfy api.CardService
+*/
+func (api *CardAPI) StreamCards(ctx context.Context, limit int, offset int) iter.Seq2[*cardModel.CardSet, error] {
+	return sliceSeq(func() (*[]*cardModel.CardSet, error) {
+		return api.IndexCards(ctx, limit, offset)
+	})
+}
+
+/*
+Search - Returns an iterator over the loaded cards that match the query, ordered by MTGJSONv4 ID. Every filter is
+applied client-side with card.CardQuery.Matches
+*/
+func (api *CardAPI) Search(ctx context.Context, query *card.CardQuery, pageSize int) iter.Seq2[*cardModel.CardSet, error] {
+	return query.Filter(api.IterCards(ctx, pageSize))
+}
+
+/*
+NewCard - Always returns ErrReadOnly
+*/
+func (api *CardAPI) NewCard(ctx context.Context, card *cardModel.CardSet, owner string) (*apiModels.APIResponse, error) {
+	return nil, ErrReadOnly
+}
+
+/*
+UpdateCard - Always returns ErrReadOnly
+*/
+func (api *CardAPI) UpdateCard(ctx context.Context, card *cardModel.CardSet, owner string) (*apiModels.APIResponse, error) {
+	return nil, ErrReadOnly
+}
+
+/*
+PatchCard - Always returns ErrReadOnly
+*/
+func (api *CardAPI) PatchCard(ctx context.Context, original *cardModel.CardSet, updated *cardModel.CardSet, owner string) (*apiModels.APIResponse, error) {
+	return nil, ErrReadOnly
+}
+
+/*
+UpsertCard - Always returns ErrReadOnly
+*/
+func (api *CardAPI) UpsertCard(ctx context.Context, card *cardModel.CardSet, owner string) (client.UpsertAction, error) {
+	return client.UpsertUnchanged, ErrReadOnly
+}
+
+/*
+DeleteCard - Always returns ErrReadOnly
+*/
+func (api *CardAPI) DeleteCard(ctx context.Context, uuid string, owner string) (*apiModels.APIResponse, error) {
+	return nil, ErrReadOnly
+}
@@ -0,0 +1,240 @@
+package offline
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	cardModel "github.com/stevezaluk/mtgjson-models/card"
+	setModel "github.com/stevezaluk/mtgjson-models/set"
+	"github.com/ulikunitz/xz"
+	"io"
+	"os"
+)
+
+/*
+ErrInvalidFile - Returned when a file is not an MTGJSON AllPrintings file or set file
+*/
+var ErrInvalidFile = errors.New("offline: not an MTGJSON AllPrintings or set file")
+
+/*
+Magic numbers used for detecting the compression of a file
+*/
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	bzip2Magic = []byte("BZh")
+	xzMagic    = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+)
+
+/*
+SetFunc - Called by ReadSets for each set in a file, along with the cards of the set in the order they appear in the
+file. Returning an error stops reading the file, and the error is returned from ReadSets
+*/
+type SetFunc func(set *setModel.Set, cards []*cardModel.CardSet) error
+
+/*
+ReadSetsFile - Read the AllPrintings file or individual set file at the path passed in the parameter, see ReadSets
+*/
+func ReadSetsFile(path string, fn SetFunc) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := ReadSets(file, fn); err != nil {
+		return fmt.Errorf("offline: failed to read %s: %w", path, err)
+	}
+
+	return nil
+}
+
+/*
+ReadSets - Read an MTGJSON AllPrintings file or individual set file from the reader passed in the parameter, calling
+fn for each set it contains. The file may be uncompressed, or compressed with gzip, bzip2 or xz, which is detected from
+its contents. AllPrintings is decoded one set at a time so that the whole file is never held in memory
+*/
+func ReadSets(reader io.Reader, fn SetFunc) error {
+	reader, err := decompress(reader)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(reader)
+
+	if err := expectDelim(decoder, '{'); err != nil {
+		return err
+	}
+
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		if key != "data" {
+			var skipped json.RawMessage
+			if err := decoder.Decode(&skipped); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err := readData(decoder, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/*
+readData - Decode the data object of a file. In AllPrintings this maps set codes to sets, while in a set file it holds
+the fields of a single set. The two are told apart by the first value of the object: in AllPrintings it is a set, which
+is always an object with a code and cards
+*/
+func readData(decoder *json.Decoder, fn SetFunc) error {
+	if err := expectDelim(decoder, '{'); err != nil {
+		return err
+	}
+
+	fields := make(map[string]json.RawMessage)
+	allPrintings := false
+
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return err
+		}
+
+		if len(fields) == 0 && !allPrintings {
+			allPrintings = isSet(value)
+		}
+
+		if !allPrintings {
+			fields[key.(string)] = value
+			continue
+		}
+
+		if err := readSet(value, fn); err != nil {
+			return fmt.Errorf("set %s: %w", key, err)
+		}
+	}
+
+	if !allPrintings {
+		data, err := json.Marshal(fields)
+		if err != nil {
+			return err
+		}
+
+		if err := readSet(data, fn); err != nil {
+			return err
+		}
+	}
+
+	return expectDelim(decoder, '}')
+}
+
+/*
+readSet - Decode a single set object and pass it and its cards to fn
+*/
+func readSet(data json.RawMessage, fn SetFunc) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	var cards []*cardModel.CardSet
+	if raw, ok := fields["cards"]; ok {
+		if err := json.Unmarshal(raw, &cards); err != nil {
+			return err
+		}
+	}
+
+	// the cards are returned separately, so they are not decoded into the set model a second time
+	delete(fields, "cards")
+	delete(fields, "tokens")
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+
+	set := new(setModel.Set)
+	if err := json.Unmarshal(data, set); err != nil {
+		return err
+	}
+
+	if set.GetCode() == "" {
+		return ErrInvalidFile
+	}
+
+	return fn(set, cards)
+}
+
+/*
+isSet - Returns true if the JSON value passed in the parameter is a set object, meaning that it has both a code and
+cards
+*/
+func isSet(value json.RawMessage) bool {
+	var probe struct {
+		Code  *string          `json:"code"`
+		Cards *json.RawMessage `json:"cards"`
+	}
+
+	if json.Unmarshal(value, &probe) != nil {
+		return false
+	}
+
+	return probe.Code != nil && probe.Cards != nil
+}
+
+/*
+expectDelim - Read the next token from the decoder and return ErrInvalidFile if it is not the delimiter passed in the
+parameter
+*/
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	if token != delim {
+		return ErrInvalidFile
+	}
+
+	return nil
+}
+
+/*
+decompress - Wrap the reader passed in the parameter in a decompressor if its contents start with the magic number of
+gzip, bzip2 or xz. Uncompressed readers are returned buffered, but otherwise unchanged
+*/
+func decompress(reader io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReaderSize(reader, 64*1024)
+
+	magic, err := buffered.Peek(len(xzMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		return gzip.NewReader(buffered)
+	case bytes.HasPrefix(magic, bzip2Magic):
+		return bzip2.NewReader(buffered), nil
+	case bytes.HasPrefix(magic, xzMagic):
+		return xz.NewReader(buffered)
+	default:
+		return buffered, nil
+	}
+}
@@ -0,0 +1,85 @@
+package offline
+
+import (
+	"errors"
+	"fmt"
+	cardModel "github.com/stevezaluk/mtgjson-models/card"
+	setModel "github.com/stevezaluk/mtgjson-models/set"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+/*
+readAll - Read the file at the path passed in the parameter and return each set as "code name: card, card", in the
+order they were read
+*/
+func readAll(path string) ([]string, error) {
+	var sets []string
+
+	err := ReadSetsFile(path, func(set *setModel.Set, cards []*cardModel.CardSet) error {
+		names := make([]string, 0, len(cards))
+		for _, card := range cards {
+			names = append(names, card.GetName())
+		}
+
+		sets = append(sets, fmt.Sprintf("%s %s: %s", set.GetCode(), set.GetName(), strings.Join(names, ", ")))
+		return nil
+	})
+
+	return sets, err
+}
+
+func TestReadSetsFile(t *testing.T) {
+	lea := "LEA Limited Edition Alpha: Lightning Bolt, Counterspell"
+	m11 := "M11 Magic 2011: Lightning Bolt"
+
+	tests := []struct {
+		name string
+		file string
+		want []string
+	}{
+		{name: "set file", file: "LEA.json", want: []string{lea}},
+		{name: "allprintings", file: "AllPrintings.json", want: []string{lea, m11}},
+		{name: "gzip", file: "LEA.json.gz", want: []string{lea}},
+		{name: "bzip2", file: "LEA.json.bz2", want: []string{lea}},
+		{name: "xz", file: "AllPrintings.json.xz", want: []string{lea, m11}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := readAll(filepath.Join("testdata", test.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !slices.Equal(got, test.want) {
+				t.Errorf("sets = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestReadSetsInvalidFile(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+	}{
+		{name: "array", file: `[]`},
+		{name: "data is not an object", file: `{"data": []}`},
+		{name: "set without a code", file: `{"data": {"name": "Limited Edition Alpha", "cards": []}}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ReadSets(strings.NewReader(test.file), func(*setModel.Set, []*cardModel.CardSet) error {
+				return nil
+			})
+
+			if !errors.Is(err, ErrInvalidFile) {
+				t.Errorf("ReadSets() error = %v, want ErrInvalidFile", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package offline
+
+import (
+	"context"
+	apiModels "github.com/stevezaluk/mtgjson-models/api"
+	cardModel "github.com/stevezaluk/mtgjson-models/card"
+	sdkErrors "github.com/stevezaluk/mtgjson-models/errors"
+	setModel "github.com/stevezaluk/mtgjson-models/set"
+	"github.com/stevezaluk/mtgjson-sdk-client/api"
+	"github.com/stevezaluk/mtgjson-sdk-client/client"
+	"iter"
+	"sort"
+)
+
+/*
+SetAPI - A read-only implementation of api.SetService that serves sets from a Store. The owner parameters are
+ignored, as the MTGJSON data files have no concept of ownership. The returned models are shared with the store and
+must not be modified
+*/
+type SetAPI struct {
+	// store - The store the sets are read from
+	store *Store
+}
+
+var _ api.SetService = (*SetAPI)(nil)
+
+/*
+GetSet - Returns the set under the set code passed in the parameter. Returns ErrNoSet if it was not loaded
+*/
+func (api *SetAPI) GetSet(ctx context.Context, code string, owner string) (*setModel.Set, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	api.store.mutex.RLock()
+	defer api.store.mutex.RUnlock()
+
+	record, ok := api.store.sets[code]
+	if !ok {
+		return nil, sdkErrors.ErrNoSet
+	}
+
+	return record.set, nil
+}
+
+/*
+IndexSets - Returns a page of the loaded sets, ordered by set code. A limit of 0 or less returns every set after the
+offset. Returns ErrNoSets if the page is empty
+*/
+func (api *SetAPI) IndexSets(ctx context.Context, limit int, offset int) (*[]*setModel.Set, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	api.store.mutex.RLock()
+	defer api.store.mutex.RUnlock()
+
+	codes := make([]string, 0, len(api.store.sets))
+	for code := range api.store.sets {
+		codes = append(codes, code)
+	}
+
+	sort.Strings(codes)
+
+	codes = page(codes, limit, offset)
+	if len(codes) == 0 {
+		return nil, sdkErrors.ErrNoSets
+	}
+
+	sets := make([]*setModel.Set, 0, len(codes))
+	for _, code := range codes {
+		sets = append(sets, api.store.sets[code].set)
+	}
+
+	return &sets, nil
+}
+
+/*
+IterSets - Returns an iterator over every loaded set, ordered by set code, see client.Paginate
+*/
+func (api *SetAPI) IterSets(ctx context.Context, pageSize int) iter.Seq2[*setModel.Set, error] {
+	return client.Paginate(ctx, pageSize, sdkErrors.ErrNoSets, api.IndexSets)
+}
+
+/*
+GetSetContents - Returns the cards of the set under the set code passed in the parameter, in the order they appear in
+the file. Returns ErrNoSet if the set was not loaded and ErrNoCards if it has no cards
+*/
+func (api *SetAPI) GetSetContents(ctx context.Context, code string, owner string) (*[]*cardModel.CardSet, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	api.store.mutex.RLock()
+	defer api.store.mutex.RUnlock()
+
+	record, ok := api.store.sets[code]
+	if !ok {
+		return nil, sdkErrors.ErrNoSet
+	}
+
+	if len(record.cards) == 0 {
+		return nil, sdkErrors.ErrNoCards
+	}
+
+	cards := make([]*cardModel.CardSet, 0, len(record.cards))
+	for _, uuid := range record.cards {
+		if card, ok := api.store.cards[uuid]; ok { // removed if another set holding the same MTGJSONv4 ID was replaced
+			cards = append(cards, card)
+		}
+	}
+
+	if len(cards) == 0 {
+		return nil, sdkErrors.ErrNoCards
+	}
+
+	return &cards, nil
+}
+
+/*
+StreamSetContents - Returns an iterator over the cards of the set under the set code passed in the parameter, in the
+same order as GetSetContents. The cards are already held in memory, so this only exists to satisfy api.SetService
+*/
+func (api *SetAPI) StreamSetContents(ctx context.Context, code string, owner string) iter.Seq2[*cardModel.CardSet, error] {
+	return sliceSeq(func() (*[]*cardModel.CardSet, error) {
+		return api.GetSetContents(ctx, code, owner)
+	})
+}
+
+/*
+NewSet - Always returns ErrReadOnly
+*/
+func (api *SetAPI) NewSet(ctx context.Context, set *setModel.Set, owner string) (*apiModels.APIResponse, error) {
+	return nil, ErrReadOnly
+}
+
+/*
+UpdateSet - Always returns ErrReadOnly
+*/
+func (api *SetAPI) UpdateSet(ctx context.Context, set *setModel.Set, owner string) (*apiModels.APIResponse, error) {
+	return nil, ErrReadOnly
+}
+
+/*
+PatchSet - Always returns ErrReadOnly
+*/
+func (api *SetAPI) PatchSet(ctx context.Context, original *setModel.Set, updated *setModel.Set, owner string) (*apiModels.APIResponse, error) {
+	return nil, ErrReadOnly
+}
+
+/*
+UpsertSet - Always returns ErrReadOnly
+*/
+func (api *SetAPI) UpsertSet(ctx context.Context, set *setModel.Set, owner string) (client.UpsertAction, error) {
+	return client.UpsertUnchanged, ErrReadOnly
+}
+
+/*
+DeleteSet - Always returns ErrReadOnly
+*/
+func (api *SetAPI) DeleteSet(ctx context.Context, code string, owner string) (*apiModels.APIResponse, error) {
+	return nil, ErrReadOnly
+}
+
+/*
+AddCards - Always returns ErrReadOnly
+*/
+func (api *SetAPI) AddCards(ctx context.Context, code string, cards []string, owner string) (*apiModels.APIResponse, error) {
+	return nil, ErrReadOnly
+}
+
+/*
+RemoveCards - Always returns ErrReadOnly
+*/
+func (api *SetAPI) RemoveCards(ctx context.Context, code string, cards []string, owner string) (*apiModels.APIResponse, error) {
+	return nil, ErrReadOnly
+}
@@ -0,0 +1,195 @@
+package offline
+
+import (
+	"fmt"
+	cardModel "github.com/stevezaluk/mtgjson-models/card"
+	sdkErrors "github.com/stevezaluk/mtgjson-models/errors"
+	setModel "github.com/stevezaluk/mtgjson-models/set"
+	"github.com/stevezaluk/mtgjson-sdk-client/client"
+	"io"
+	"iter"
+	"sort"
+	"sync"
+)
+
+/*
+ErrReadOnly - Returned by each mutation of the offline backend. It wraps ErrInvalidPermissions so that callers can
+handle it the same way as a mutation that was rejected by the API
+*/
+var ErrReadOnly = fmt.Errorf("offline: the backend is read-only: %w", sdkErrors.ErrInvalidPermissions)
+
+/*
+setRecord - A set held in the store along with the MTGJSONv4 IDs of its cards
+*/
+type setRecord struct {
+	// set - The set model, without its cards
+	set *setModel.Set
+
+	// cards - The MTGJSONv4 IDs of the cards in the set, in the order they appear in the file
+	cards []string
+}
+
+/*
+Store - The cards and sets loaded from MTGJSON data files. A Store is safe for concurrent use, and files can be loaded
+into it while it is serving reads. Use Card and Set to get the read-only services that are backed by it
+*/
+type Store struct {
+	// cards - The loaded cards keyed by their MTGJSONv4 ID
+	cards map[string]*cardModel.CardSet
+
+	// sets - The loaded sets keyed by their set code
+	sets map[string]*setRecord
+
+	// sortedCards - The MTGJSONv4 IDs of the loaded cards in sorted order, used for paging through them. This is
+	// built on demand and discarded whenever a set is loaded
+	sortedCards []string
+
+	// mutex - Guards the cards, sets and sorted card IDs
+	mutex sync.RWMutex
+
+	// sortedMutex - Serializes building the sorted card IDs between readers that hold the mutex for reading
+	sortedMutex sync.Mutex
+}
+
+/*
+New - Create a new, empty Store
+*/
+func New() *Store {
+	return &Store{
+		cards: make(map[string]*cardModel.CardSet),
+		sets:  make(map[string]*setRecord),
+	}
+}
+
+/*
+Open - Create a new Store and load each of the files passed in the parameter into it, see Store.LoadFile
+*/
+func Open(paths ...string) (*Store, error) {
+	store := New()
+
+	for _, path := range paths {
+		if err := store.LoadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	return store, nil
+}
+
+/*
+LoadFile - Load the AllPrintings file or individual set file at the path passed in the parameter, see Store.Load
+*/
+func (store *Store) LoadFile(path string) error {
+	return ReadSetsFile(path, store.addSet)
+}
+
+/*
+Load - Load an MTGJSON AllPrintings file or individual set file from the reader passed in the parameter, see
+ReadSets for the supported formats. Sets and cards that were already loaded are replaced
+*/
+func (store *Store) Load(reader io.Reader) error {
+	return ReadSets(reader, store.addSet)
+}
+
+/*
+addSet - Add a set and its cards to the store. Cards without an MTGJSONv4 ID cannot be looked up, so they are skipped.
+If the set was already loaded, its previous cards are removed first so that printings dropped from the set do not remain
+*/
+func (store *Store) addSet(set *setModel.Set, cards []*cardModel.CardSet) error {
+	record := &setRecord{set: set, cards: make([]string, 0, len(cards))}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if previous, ok := store.sets[set.GetCode()]; ok {
+		for _, uuid := range previous.cards {
+			delete(store.cards, uuid)
+		}
+	}
+
+	for _, card := range cards {
+		uuid := card.GetIdentifiers().GetMtgjsonV4Id()
+		if uuid == "" {
+			continue
+		}
+
+		store.cards[uuid] = card
+		record.cards = append(record.cards, uuid)
+	}
+
+	store.sets[set.GetCode()] = record
+	store.sortedCards = nil
+
+	return nil
+}
+
+/*
+sortedCardIds - Returns the MTGJSONv4 IDs of the loaded cards in sorted order, building them if a set was loaded since
+they were last requested. Must be called with the mutex held, so that the cards cannot change until the caller has
+read the ones it needs. The returned slice must not be modified
+*/
+func (store *Store) sortedCardIds() []string {
+	store.sortedMutex.Lock()
+	defer store.sortedMutex.Unlock()
+
+	if store.sortedCards == nil {
+		store.sortedCards = make([]string, 0, len(store.cards))
+		for uuid := range store.cards {
+			store.sortedCards = append(store.sortedCards, uuid)
+		}
+
+		sort.Strings(store.sortedCards)
+	}
+
+	return store.sortedCards
+}
+
+/*
+page - Returns the slice of items passed in the parameter that a limit and offset select. A limit of 0 or less selects
+every item after the offset
+*/
+func page[T any](items []T, limit int, offset int) []T {
+	offset = min(max(offset, 0), len(items))
+	items = items[offset:]
+
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+
+	return items
+}
+
+/*
+sliceSeq - Returns an iterator over the items returned by fetch, which is only called once the iterator is advanced.
+An error returned by fetch is yielded once
+*/
+func sliceSeq[T any](fetch func() (*[]*T, error)) iter.Seq2[*T, error] {
+	return client.Seq(func(fn func(item *T) error) error {
+		items, err := fetch()
+		if err != nil {
+			return err
+		}
+
+		for _, item := range *items {
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+/*
+Card - Returns a read-only CardAPI backed by the store
+*/
+func (store *Store) Card() *CardAPI {
+	return &CardAPI{store: store}
+}
+
+/*
+Set - Returns a read-only SetAPI backed by the store
+*/
+func (store *Store) Set() *SetAPI {
+	return &SetAPI{store: store}
+}
@@ -0,0 +1,99 @@
+package offline
+
+import (
+	"context"
+	"errors"
+	sdkErrors "github.com/stevezaluk/mtgjson-models/errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLoadReplacesSet(t *testing.T) {
+	store := New()
+
+	first := `{"data": {"code": "LEA", "name": "Limited Edition Alpha", "cards": [
+		{"name": "Lightning Bolt", "identifiers": {"mtgjsonV4Id": "00000000-0000-0000-0000-000000000001"}},
+		{"name": "Counterspell", "identifiers": {"mtgjsonV4Id": "00000000-0000-0000-0000-000000000002"}}
+	]}}`
+	second := `{"data": {"code": "LEA", "name": "Limited Edition Alpha", "cards": [
+		{"name": "Lightning Bolt", "identifiers": {"mtgjsonV4Id": "00000000-0000-0000-0000-000000000001"}}
+	]}}`
+
+	for _, file := range []string{first, second} {
+		if err := store.Load(strings.NewReader(file)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := context.Background()
+
+	if _, err := store.Card().GetCard(ctx, "00000000-0000-0000-0000-000000000002", ""); !errors.Is(err, sdkErrors.ErrNoCard) {
+		t.Errorf("GetCard() for a printing dropped from the set error = %v, want ErrNoCard", err)
+	}
+
+	cards, err := store.Card().IndexCards(ctx, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(*cards) != 1 || (*cards)[0].GetName() != "Lightning Bolt" {
+		t.Errorf("IndexCards() returned %d cards, want only Lightning Bolt", len(*cards))
+	}
+}
+
+func TestReadWhileLoading(t *testing.T) {
+	store := New()
+
+	full := `{"data": {"code": "LEA", "name": "Limited Edition Alpha", "cards": [
+		{"name": "Lightning Bolt", "identifiers": {"mtgjsonV4Id": "00000000-0000-0000-0000-000000000001"}},
+		{"name": "Counterspell", "identifiers": {"mtgjsonV4Id": "00000000-0000-0000-0000-000000000002"}}
+	]}}`
+	reduced := `{"data": {"code": "LEA", "name": "Limited Edition Alpha", "cards": [
+		{"name": "Lightning Bolt", "identifiers": {"mtgjsonV4Id": "00000000-0000-0000-0000-000000000001"}}
+	]}}`
+
+	if err := store.Load(strings.NewReader(full)); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		for index := range 500 {
+			if err := store.Load(strings.NewReader([]string{reduced, full}[index%2])); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+
+	ctx := context.Background()
+
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+
+		cards, err := store.Card().IndexCards(ctx, 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if slices.Contains(*cards, nil) {
+			t.Fatal("IndexCards() returned a card that was removed while the page was read")
+		}
+
+		contents, err := store.Set().GetSetContents(ctx, "LEA", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if slices.Contains(*contents, nil) {
+			t.Fatal("GetSetContents() returned a card that was removed while the page was read")
+		}
+	}
+}
@@ -1,11 +1,15 @@
 package set
 
 import (
+	"context"
+	"errors"
 	apiModels "github.com/stevezaluk/mtgjson-models/api"
 	cardModel "github.com/stevezaluk/mtgjson-models/card"
 	sdkErrors "github.com/stevezaluk/mtgjson-models/errors"
 	setModel "github.com/stevezaluk/mtgjson-models/set"
 	"github.com/stevezaluk/mtgjson-sdk-client/client"
+	"google.golang.org/protobuf/proto"
+	"iter"
 	"net/http"
 )
 
@@ -21,13 +25,19 @@ type SetAPI struct {
 }
 
 /*
-New Create a new instance of the SetAPI struct
+New Create a new instance of the SetAPI struct. The base URL must be an absolute http(s) URL and may
+contain a path prefix, client.ErrInvalidBaseURL is returned if it is malformed
 */
-func New(baseUrl string, client *client.HTTPClient) *SetAPI {
-	return &SetAPI{
-		baseUrl: baseUrl + "/set",
-		client:  client,
+func New(baseUrl string, httpClient *client.HTTPClient) (*SetAPI, error) {
+	endpoint, err := client.JoinURL(baseUrl, "set")
+	if err != nil {
+		return nil, err
 	}
+
+	return &SetAPI{
+		baseUrl: endpoint,
+		client:  httpClient,
+	}, nil
 }
 
 /*
@@ -44,63 +54,63 @@ func (api *SetAPI) Client() *client.HTTPClient {
 	return api.client
 }
 
+/*
+getSetErrors - Maps the status codes returned from GET /set to sentinel errors
+*/
+var getSetErrors = client.ErrorMap{
+	http.StatusNotFound: {Default: sdkErrors.ErrNoSet},
+}
+
 /*
 GetSet Takes a single string representing a set code and returns a set model for the set.
 Returns ErrNoSet if the set does not exist, or cannot be located
 */
-func (api *SetAPI) GetSet(code string, owner string) (*setModel.Set, error) {
-	request := api.client.BuildRequest(&setModel.Set{}).
+func (api *SetAPI) GetSet(ctx context.Context, code string, owner string) (*setModel.Set, error) {
+	request := api.client.BuildRequest(ctx).
 		SetQueryParams(map[string]string{"setCode": code, "owner": owner})
 
-	resp, err := request.Get(api.baseUrl)
-	if err != nil {
-		return nil, err
-	}
-
-	if resp.Error() != nil {
-		if resp.StatusCode() == http.StatusUnauthorized {
-			return nil, sdkErrors.ErrTokenInvalid
-		}
-
-		if resp.StatusCode() == http.StatusForbidden {
-			return nil, sdkErrors.ErrInvalidPermissions
-		}
-
-		if resp.StatusCode() == http.StatusNotFound {
-			return nil, sdkErrors.ErrNoSet
-		}
-	}
-
-	return resp.Result().(*setModel.Set), nil
+	return client.Execute[setModel.Set](request, http.MethodGet, api.baseUrl, getSetErrors)
 }
 
 /*
-IndexSets Returns all sets in the database unmarshalled as card models. The limit parameter
-will be passed directly to the database query to limit the number of models returned
+indexSetsErrors - Maps the status codes returned from GET /set (without a set code) to sentinel errors
 */
-func (api *SetAPI) IndexSets(limit int) (*[]*setModel.Set, error) {
-	request := api.client.BuildRequest(&[]*setModel.Set{})
-
-	resp, err := request.Get(api.baseUrl)
-	if err != nil {
-		return nil, err
-	}
+var indexSetsErrors = client.ErrorMap{
+	http.StatusNotFound:   {Default: sdkErrors.ErrNoSets},
+	http.StatusBadRequest: {Default: sdkErrors.ErrNoSets},
+}
 
-	if resp.Error() != nil {
-		if resp.StatusCode() == http.StatusUnauthorized {
-			return nil, sdkErrors.ErrTokenInvalid
-		}
+/*
+IndexSets Returns a page of sets from the database unmarshalled as set models. The limit and offset
+parameters are passed directly to the database query to page through the sets, a value of 0 or less
+omits them. Returns ErrNoSets if the page is empty
+*/
+func (api *SetAPI) IndexSets(ctx context.Context, limit int, offset int) (*[]*setModel.Set, error) {
+	request := api.client.BuildRequest(ctx).SetQueryParams(client.PageParams(limit, offset))
 
-		if resp.StatusCode() == http.StatusForbidden {
-			return nil, sdkErrors.ErrInvalidPermissions
-		}
+	return client.Execute[[]*setModel.Set](request, http.MethodGet, api.baseUrl, indexSetsErrors)
+}
 
-		if resp.StatusCode() == http.StatusBadRequest {
-			return nil, sdkErrors.ErrNoSets
-		}
-	}
+/*
+IterSets Returns an iterator over every set in the database. Pages of pageSize sets are fetched lazily
+as the iterator is advanced (client.DefaultPageSize if pageSize is 0 or less). An error fetching a page,
+including cancellation of the context, is yielded once and ends the iteration
+*/
+func (api *SetAPI) IterSets(ctx context.Context, pageSize int) iter.Seq2[*setModel.Set, error] {
+	return client.Paginate(ctx, pageSize, sdkErrors.ErrNoSets, api.IndexSets)
+}
 
-	return resp.Result().(*[]*setModel.Set), nil
+/*
+newSetErrors - Maps the status codes returned from POST /set to sentinel errors
+*/
+var newSetErrors = client.ErrorMap{
+	http.StatusConflict: {Default: sdkErrors.ErrSetAlreadyExists},
+	http.StatusBadRequest: {Match: []error{
+		sdkErrors.ErrInvalidObjectStructure,
+		sdkErrors.ErrSetMissingId,
+		sdkErrors.ErrMetaApiMustBeNull,
+		sdkErrors.ErrInvalidCards,
+	}},
 }
 
 /*
@@ -109,216 +119,233 @@ valid name and set code, additionally the set cannot already exist under the sam
 the email address of the owner you want to assign the deck to. If the string is empty (i.e. == ""), it
 will be assigned to the system user
 */
-func (api *SetAPI) NewSet(set *setModel.Set, owner string) (*apiModels.APIResponse, error) {
-	request := api.client.BuildRequest(&apiModels.APIResponse{}).SetQueryParam("owner", owner).SetBody(set)
+func (api *SetAPI) NewSet(ctx context.Context, set *setModel.Set, owner string) (*apiModels.APIResponse, error) {
+	request := api.client.BuildRequest(ctx).SetQueryParam("owner", owner).SetBody(set)
 
-	resp, err := request.Post(api.baseUrl)
+	return client.Execute[apiModels.APIResponse](request, http.MethodPost, api.baseUrl, newSetErrors)
+}
+
+/*
+updateSetErrors - Maps the status codes returned from PUT and PATCH /set to sentinel errors
+*/
+var updateSetErrors = client.ErrorMap{
+	http.StatusNotFound: {Default: sdkErrors.ErrNoSet},
+	http.StatusBadRequest: {Match: []error{
+		sdkErrors.ErrMetaApiMustBeNull,
+		sdkErrors.ErrSetMissingId,
+	}},
+	http.StatusInternalServerError: {Default: sdkErrors.ErrSetUpdateFailed},
+}
+
+/*
+UpdateSet Replace the set stored under the code of the set model passed in the parameter, keeping its
+owner and contents. The API metadata of the model is not sent, as the API maintains it, but its modified
+date is used to detect conflicting changes: if the stored set was modified after the model was fetched
+then client.ErrConflict is returned and nothing is changed. A model without metadata replaces the set
+unconditionally. Returns ErrSetMissingId if the model is nil. PUT /set, the If-Match header and the 412
+mapped to client.ErrConflict require a server that supports them, which the upstream MTGJSON API does
+not provide yet
+*/
+func (api *SetAPI) UpdateSet(ctx context.Context, set *setModel.Set, owner string) (*apiModels.APIResponse, error) {
+	body, err := withoutMeta(set)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.Error() != nil {
-		errorResponse := resp.Error().(*apiModels.APIResponse)
-
-		if resp.StatusCode() == http.StatusUnauthorized {
-			return errorResponse, sdkErrors.ErrTokenInvalid
-		}
-
-		if resp.StatusCode() == http.StatusForbidden {
-			return errorResponse, sdkErrors.ErrInvalidPermissions
-		}
-
-		if resp.StatusCode() == http.StatusConflict {
-			return errorResponse, sdkErrors.ErrSetAlreadyExists
-		}
-
-		if resp.StatusCode() == http.StatusBadRequest {
-			if errorResponse.Err == sdkErrors.ErrInvalidObjectStructure.Error() {
-				return errorResponse, sdkErrors.ErrInvalidObjectStructure
-			}
-
-			if errorResponse.Err == sdkErrors.ErrSetMissingId.Error() {
-				return errorResponse, sdkErrors.ErrSetMissingId
-			}
-
-			if errorResponse.Err == sdkErrors.ErrMetaApiMustBeNull.Error() {
-				return errorResponse, sdkErrors.ErrMetaApiMustBeNull
-			}
-
-			if errorResponse.Err == sdkErrors.ErrInvalidCards.Error() {
-				return errorResponse, sdkErrors.ErrInvalidCards
-			}
-		}
-	}
+	request := client.SetIfMatch(api.client.BuildRequest(ctx), set.GetMtgjsonApiMeta().GetModifiedDate()).
+		SetQueryParams(map[string]string{"setCode": set.GetCode(), "owner": owner}).
+		SetBody(body)
 
-	return resp.Result().(*apiModels.APIResponse), nil
+	return client.Execute[apiModels.APIResponse](request, http.MethodPut, api.baseUrl, updateSetErrors)
 }
 
 /*
-DeleteSet Remove a set from the MongoDB database using the code passed in the parameter.
-Returns ErrNoSet if the set does not exist. Returns ErrSetDeleteFailed if the deleted count
-does not equal 1
+PatchSet Update only the fields that differ between the original set model, as it was fetched, and the
+updated one, by sending a JSON merge patch. Fields changed concurrently by someone else are left intact,
+however the modified date of original is still used to detect conflicts in the same way as UpdateSet. Returns
+ErrSetMissingId if either model is nil. Like UpdateSet, this requires PATCH /set support on the server
 */
-func (api *SetAPI) DeleteSet(code string, owner string) (*apiModels.APIResponse, error) {
-	request := api.client.BuildRequest(&apiModels.APIResponse{}).SetQueryParams(map[string]string{"setCode": code, "owner": owner})
-
-	resp, err := request.Delete(api.baseUrl)
+func (api *SetAPI) PatchSet(ctx context.Context, original *setModel.Set, updated *setModel.Set, owner string) (*apiModels.APIResponse, error) {
+	originalBody, err := withoutMeta(original)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.Error() != nil {
-		errorResponse := resp.Error().(*apiModels.APIResponse)
-
-		if resp.StatusCode() == http.StatusUnauthorized {
-			return errorResponse, sdkErrors.ErrTokenInvalid
-		}
-
-		if resp.StatusCode() == http.StatusForbidden {
-			return errorResponse, sdkErrors.ErrInvalidPermissions
-		}
-
-		if resp.StatusCode() == http.StatusNotFound {
-			return errorResponse, sdkErrors.ErrNoSet
-		}
+	updatedBody, err := withoutMeta(updated)
+	if err != nil {
+		return nil, err
+	}
 
-		if resp.StatusCode() == http.StatusInternalServerError {
-			return errorResponse, sdkErrors.ErrSetDeleteFailed
-		}
+	patch, err := client.MergePatch(originalBody, updatedBody)
+	if err != nil {
+		return nil, err
 	}
 
-	return resp.Result().(*apiModels.APIResponse), nil
+	request := client.SetIfMatch(api.client.BuildRequest(ctx), original.GetMtgjsonApiMeta().GetModifiedDate()).
+		SetQueryParams(map[string]string{"setCode": original.GetCode(), "owner": owner}).
+		SetHeader("Content-Type", client.MergePatchContentType).
+		SetBody(patch)
+
+	return client.Execute[apiModels.APIResponse](request, http.MethodPatch, api.baseUrl, updateSetErrors)
 }
 
 /*
-GetSetContents Return a list of CardSet models representing the contents of a specific set
+UpsertSet Create the set passed in the parameter if no set exists under its code, or update the existing
+set if any of the fields set in the set model differ from it. Fields that the model leaves unset, or sets to
+their zero value, keep their stored value, see client.Overlay; use UpdateSet or PatchSet to clear them. The
+contents of the set are not compared or changed. The API metadata of both models is ignored when comparing
+them, and the update is sent with PatchSet, so client.ErrConflict is returned if the set is modified
+concurrently. Returns the action that was taken, or ErrSetMissingId without sending a request if the model
+is nil or has no code
 */
-func (api *SetAPI) GetSetContents(code string, owner string) (*[]*cardModel.CardSet, error) {
-	request := api.client.BuildRequest(&[]*cardModel.CardSet{}).SetQueryParams(map[string]string{"setCode": code, "owner": owner})
-
-	resp, err := request.Get(api.baseUrl + "/content")
+func (api *SetAPI) UpsertSet(ctx context.Context, set *setModel.Set, owner string) (client.UpsertAction, error) {
+	desired, err := withoutMeta(set)
 	if err != nil {
-		return nil, err
+		return client.UpsertUnchanged, err
 	}
 
-	if resp.Error() != nil {
-		if resp.StatusCode() == http.StatusUnauthorized {
-			return nil, sdkErrors.ErrTokenInvalid
-		}
-
-		if resp.StatusCode() == http.StatusForbidden {
-			return nil, sdkErrors.ErrInvalidPermissions
-		}
+	if set.GetCode() == "" { // an empty code would fetch the set index instead of a single set
+		return client.UpsertUnchanged, sdkErrors.ErrSetMissingId
+	}
 
-		if resp.StatusCode() == http.StatusNotFound {
-			return nil, sdkErrors.ErrNoSet
+	existing, err := api.GetSet(ctx, set.GetCode(), owner)
+	if errors.Is(err, sdkErrors.ErrNoSet) {
+		if _, err := api.NewSet(ctx, desired, owner); err != nil {
+			return client.UpsertUnchanged, err
 		}
 
-		if resp.StatusCode() == http.StatusBadRequest {
-			errorResponse := resp.Error().(*apiModels.APIResponse)
-			if errorResponse.Err == sdkErrors.ErrSetMissingId.Error() {
-				return nil, sdkErrors.ErrSetMissingId
-			} else {
-				return nil, sdkErrors.ErrNoCards
-			}
-		}
+		return client.UpsertCreated, nil
 	}
 
-	return resp.Result().(*[]*cardModel.CardSet), nil
-}
-
-/*
-AddCards Add an instance of a card to a set
-*/
-func (api *SetAPI) AddCards(code string, cards []string, owner string) (*apiModels.APIResponse, error) {
-	request := api.client.BuildRequest(&apiModels.APIResponse{}).SetQueryParams(map[string]string{"setCode": code, "owner": owner}).SetBody(cards)
+	if err != nil {
+		return client.UpsertUnchanged, err
+	}
 
-	resp, err := request.Post(api.baseUrl + "/content")
+	current, err := withoutMeta(existing)
 	if err != nil {
-		return nil, err
+		return client.UpsertUnchanged, err
 	}
 
-	if resp.Error() != nil {
-		errorResponse := resp.Error().(*apiModels.APIResponse)
+	merged, err := client.Overlay(current, desired)
+	if err != nil {
+		return client.UpsertUnchanged, err
+	}
 
-		if resp.StatusCode() == http.StatusUnauthorized {
-			return errorResponse, sdkErrors.ErrTokenInvalid
-		}
+	if proto.Equal(current, merged) {
+		return client.UpsertUnchanged, nil
+	}
 
-		if resp.StatusCode() == http.StatusForbidden {
-			return errorResponse, sdkErrors.ErrInvalidPermissions
-		}
+	if _, err := api.PatchSet(ctx, existing, merged, owner); err != nil {
+		return client.UpsertUnchanged, err
+	}
 
-		if resp.StatusCode() == http.StatusNotFound {
-			return errorResponse, sdkErrors.ErrNoSet
-		}
+	return client.UpsertUpdated, nil
+}
 
-		if resp.StatusCode() == http.StatusBadRequest {
-			if errorResponse.Err == sdkErrors.ErrSetMissingId.Error() {
-				return errorResponse, sdkErrors.ErrSetMissingId
-			}
+/*
+withoutMeta - Returns a copy of the set model with its API metadata removed, as the API rejects models that carry it.
+Returns ErrSetMissingId if the model is nil
+*/
+func withoutMeta(set *setModel.Set) (*setModel.Set, error) {
+	if set == nil {
+		return nil, sdkErrors.ErrSetMissingId
+	}
 
-			if errorResponse.Err == sdkErrors.ErrSetNoCards.Error() {
-				return errorResponse, sdkErrors.ErrSetNoCards
-			}
-		}
+	set = proto.Clone(set).(*setModel.Set)
+	set.MtgjsonApiMeta = nil
 
-		if resp.StatusCode() == http.StatusInternalServerError {
-			return errorResponse, sdkErrors.ErrSetUpdateFailed
-		}
-	}
+	return set, nil
+}
 
-	return resp.Result().(*apiModels.APIResponse), nil
+/*
+deleteSetErrors - Maps the status codes returned from DELETE /set to sentinel errors
+*/
+var deleteSetErrors = client.ErrorMap{
+	http.StatusNotFound:            {Default: sdkErrors.ErrNoSet},
+	http.StatusInternalServerError: {Default: sdkErrors.ErrSetDeleteFailed},
 }
 
 /*
-RemoveCards Remove all instances of a card in a set
+DeleteSet Remove a set from the MongoDB database using the code passed in the parameter.
+Returns ErrNoSet if the set does not exist. Returns ErrSetDeleteFailed if the deleted count
+does not equal 1
 */
-func (api *SetAPI) RemoveCards(code string, cards []string, owner string) (*apiModels.APIResponse, error) {
-	request := api.client.BuildRequest(&apiModels.APIResponse{}).SetQueryParams(map[string]string{"setCode": code, "owner": owner}).SetBody(cards)
+func (api *SetAPI) DeleteSet(ctx context.Context, code string, owner string) (*apiModels.APIResponse, error) {
+	request := api.client.BuildRequest(ctx).SetQueryParams(map[string]string{"setCode": code, "owner": owner})
 
-	resp, err := request.Delete(api.baseUrl + "/content")
-	if err != nil {
-		return nil, err
-	}
+	return client.Execute[apiModels.APIResponse](request, http.MethodDelete, api.baseUrl, deleteSetErrors)
+}
 
-	if resp.Error() != nil {
-		errorResponse := resp.Error().(*apiModels.APIResponse)
+/*
+getSetContentsErrors - Maps the status codes returned from GET /set/content to sentinel errors
+*/
+var getSetContentsErrors = client.ErrorMap{
+	http.StatusNotFound:   {Default: sdkErrors.ErrNoSet},
+	http.StatusBadRequest: {Match: []error{sdkErrors.ErrSetMissingId}, Default: sdkErrors.ErrNoCards},
+}
 
-		if resp.StatusCode() == http.StatusUnauthorized {
-			return errorResponse, sdkErrors.ErrTokenInvalid
-		}
+/*
+GetSetContents Return a list of CardSet models representing the contents of a specific set
+*/
+func (api *SetAPI) GetSetContents(ctx context.Context, code string, owner string) (*[]*cardModel.CardSet, error) {
+	request := api.client.BuildRequest(ctx).SetQueryParams(map[string]string{"setCode": code, "owner": owner})
 
-		if resp.StatusCode() == http.StatusForbidden {
-			return errorResponse, sdkErrors.ErrInvalidPermissions
-		}
+	return client.Execute[[]*cardModel.CardSet](request, http.MethodGet, api.baseUrl+"/content", getSetContentsErrors)
+}
 
-		if resp.StatusCode() == http.StatusNotFound {
-			return errorResponse, sdkErrors.ErrNoSet
-		}
+/*
+StreamSetContents Returns an iterator over the contents of a specific set. Unlike GetSetContents, the
+response is decoded one card at a time as the iterator is advanced, so that large sets never have to be
+held in memory as a whole. An error is yielded once and ends the iteration
+*/
+func (api *SetAPI) StreamSetContents(ctx context.Context, code string, owner string) iter.Seq2[*cardModel.CardSet, error] {
+	return client.Seq(func(fn func(card *cardModel.CardSet) error) error {
+		request := api.client.BuildRequest(ctx).SetQueryParams(map[string]string{"setCode": code, "owner": owner})
 
-		if resp.StatusCode() == http.StatusBadRequest {
-			if errorResponse.Err == sdkErrors.ErrSetMissingId.Error() {
-				return errorResponse, sdkErrors.ErrSetMissingId
-			}
+		return client.Stream(request, http.MethodGet, api.baseUrl+"/content", getSetContentsErrors, fn)
+	})
+}
 
-			if errorResponse.Err == sdkErrors.ErrSetNoCards.Error() {
-				return errorResponse, sdkErrors.ErrSetNoCards
-			}
+/*
+addCardsErrors - Maps the status codes returned from POST /set/content to sentinel errors
+*/
+var addCardsErrors = client.ErrorMap{
+	http.StatusNotFound: {Default: sdkErrors.ErrNoSet},
+	http.StatusBadRequest: {Match: []error{
+		sdkErrors.ErrSetMissingId,
+		sdkErrors.ErrSetNoCards,
+	}},
+	http.StatusInternalServerError: {Default: sdkErrors.ErrSetUpdateFailed},
+}
 
-			if errorResponse.Err == sdkErrors.ErrInvalidCards.Error() {
-				return errorResponse, sdkErrors.ErrInvalidCards
-			}
+/*
+AddCards Add an instance of a card to a set
+*/
+func (api *SetAPI) AddCards(ctx context.Context, code string, cards []string, owner string) (*apiModels.APIResponse, error) {
+	request := api.client.BuildRequest(ctx).SetQueryParams(map[string]string{"setCode": code, "owner": owner}).SetBody(cards)
 
-			if errorResponse.Err == sdkErrors.ErrInvalidObjectStructure.Error() {
-				return errorResponse, sdkErrors.ErrInvalidObjectStructure
-			}
-		}
+	return client.Execute[apiModels.APIResponse](request, http.MethodPost, api.baseUrl+"/content", addCardsErrors)
+}
 
-		if resp.StatusCode() == http.StatusInternalServerError {
-			return errorResponse, sdkErrors.ErrSetUpdateFailed
-		}
-	}
+/*
+removeCardsErrors - Maps the status codes returned from DELETE /set/content to sentinel errors
+*/
+var removeCardsErrors = client.ErrorMap{
+	http.StatusNotFound: {Default: sdkErrors.ErrNoSet},
+	http.StatusBadRequest: {Match: []error{
+		sdkErrors.ErrSetMissingId,
+		sdkErrors.ErrSetNoCards,
+		sdkErrors.ErrInvalidCards,
+		sdkErrors.ErrInvalidObjectStructure,
+	}},
+	http.StatusInternalServerError: {Default: sdkErrors.ErrSetUpdateFailed},
+}
+
+/*
+RemoveCards Remove all instances of a card in a set
+*/
+func (api *SetAPI) RemoveCards(ctx context.Context, code string, cards []string, owner string) (*apiModels.APIResponse, error) {
+	request := api.client.BuildRequest(ctx).SetQueryParams(map[string]string{"setCode": code, "owner": owner}).SetBody(cards)
 
-	return resp.Result().(*apiModels.APIResponse), nil
+	return client.Execute[apiModels.APIResponse](request, http.MethodDelete, api.baseUrl+"/content", removeCardsErrors)
 }
@@ -0,0 +1,264 @@
+package testserver
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/auth0/go-auth0/authentication/oauth"
+	apiModels "github.com/stevezaluk/mtgjson-models/api"
+	sdkErrors "github.com/stevezaluk/mtgjson-models/errors"
+	userModel "github.com/stevezaluk/mtgjson-models/user"
+	"net/http"
+	"net/mail"
+	"time"
+)
+
+/*
+minPasswordLength - The minimum length of a password accepted by /register and /login
+*/
+const minPasswordLength = 8
+
+/*
+userRecord - A registered user held in the store of the server along with their password
+*/
+type userRecord struct {
+	// user - The stored user model
+	user *userModel.User
+
+	// password - The password of the user, compared in plain text by /login
+	password string
+
+	// resets - The number of times a reset password email was requested for the user
+	resets int
+}
+
+/*
+issuedToken - An access token issued by the server
+*/
+type issuedToken struct {
+	// email - The email address of the user the token was issued to
+	email string
+
+	// expiry - The time at which the token stops being accepted
+	expiry time.Time
+}
+
+/*
+refreshRequest - The request body of POST /refresh
+*/
+type refreshRequest struct {
+	// RefreshToken - The refresh token issued alongside the access token
+	RefreshToken string `json:"refresh_token"`
+}
+
+/*
+validateCredentials - Returns ErrInvalidEmail if the email address is malformed and ErrInvalidPasswordLength if the
+password is too short
+*/
+func validateCredentials(email string, password string) error {
+	if _, err := mail.ParseAddress(email); err != nil {
+		return sdkErrors.ErrInvalidEmail
+	}
+
+	if len(password) < minPasswordLength {
+		return sdkErrors.ErrInvalidPasswordLength
+	}
+
+	return nil
+}
+
+/*
+AddUser - Register a user without going through POST /register. Returns the same errors as POST /register
+*/
+func (server *Server) AddUser(email string, username string, password string) error {
+	if err := validateCredentials(email, password); err != nil {
+		return err
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	if _, ok := server.users[email]; ok {
+		return sdkErrors.ErrUserAlreadyExist
+	}
+
+	server.users[email] = &userRecord{
+		user:     &userModel.User{Username: username, Email: email},
+		password: password,
+	}
+
+	return nil
+}
+
+/*
+IssueToken - Issue a token set for the registered user under the email address passed in the parameter, without going
+through POST /login. Returns ErrNoUser if the user does not exist
+*/
+func (server *Server) IssueToken(email string) (*oauth.TokenSet, error) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	if _, ok := server.users[email]; !ok {
+		return nil, sdkErrors.ErrNoUser
+	}
+
+	return server.issueToken(email), nil
+}
+
+/*
+PasswordResets - Returns the number of times a reset password email was requested with GET /reset for the user under
+the email address passed in the parameter
+*/
+func (server *Server) PasswordResets(email string) int {
+	server.mutex.RLock()
+	defer server.mutex.RUnlock()
+
+	if record, ok := server.users[email]; ok {
+		return record.resets
+	}
+
+	return 0
+}
+
+/*
+issueToken - Generate a new access and refresh token for the user. The caller must hold the mutex
+*/
+func (server *Server) issueToken(email string) *oauth.TokenSet {
+	token := &oauth.TokenSet{
+		AccessToken:  randomToken(),
+		RefreshToken: randomToken(),
+		ExpiresIn:    int64(server.tokenLifetime / time.Second),
+		Scope:        "openid profile email offline_access",
+		TokenType:    "Bearer",
+	}
+
+	server.accessTokens[token.AccessToken] = issuedToken{email: email, expiry: server.now().Add(server.tokenLifetime)}
+	server.refreshTokens[token.RefreshToken] = email
+
+	return token
+}
+
+/*
+tokenOwner - Returns the email address of the user the access token was issued to. Returns an empty string if the
+token is unknown or has expired
+*/
+func (server *Server) tokenOwner(token string) string {
+	server.mutex.RLock()
+	defer server.mutex.RUnlock()
+
+	issued, ok := server.accessTokens[token]
+	if !ok || server.now().After(issued.expiry) {
+		return ""
+	}
+
+	return issued.email
+}
+
+/*
+randomToken - Returns a random, opaque token
+*/
+func randomToken() string {
+	buf := make([]byte, 24)
+	_, _ = rand.Read(buf) // crypto/rand never returns an error
+
+	return hex.EncodeToString(buf)
+}
+
+/*
+login - POST /login. Exchanges the email address and password in the request body for a token set
+*/
+func (server *Server) login(writer http.ResponseWriter, request *http.Request) {
+	credentials, err := decodeBody[apiModels.LoginRequest](request)
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, sdkErrors.ErrInvalidEmail)
+		return
+	}
+
+	if err := validateCredentials(credentials.Email, credentials.Password); err != nil {
+		writeError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	record, ok := server.users[credentials.Email]
+	if !ok {
+		writeError(writer, http.StatusNotFound, sdkErrors.ErrNoUser)
+		return
+	}
+
+	if record.password != credentials.Password {
+		writeError(writer, http.StatusForbidden, sdkErrors.ErrInvalidPermissions)
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, server.issueToken(credentials.Email))
+}
+
+/*
+refresh - POST /refresh. Exchanges the refresh token in the request body for a new token set. Refresh tokens are
+rotated, so each one can only be used once
+*/
+func (server *Server) refresh(writer http.ResponseWriter, request *http.Request) {
+	body, err := decodeBody[refreshRequest](request)
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, sdkErrors.ErrTokenInvalid)
+		return
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	email, ok := server.refreshTokens[body.RefreshToken]
+	if !ok {
+		writeError(writer, http.StatusBadRequest, sdkErrors.ErrTokenInvalid)
+		return
+	}
+
+	delete(server.refreshTokens, body.RefreshToken)
+
+	writeJSON(writer, http.StatusOK, server.issueToken(email))
+}
+
+/*
+register - POST /register. Registers the user in the request body
+*/
+func (server *Server) register(writer http.ResponseWriter, request *http.Request) {
+	body, err := decodeBody[apiModels.RegisterRequest](request)
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, sdkErrors.ErrInvalidEmail)
+		return
+	}
+
+	err = server.AddUser(body.Email, body.Username, body.Password)
+	if err != nil {
+		writeError(writer, errorStatus(err), err)
+		return
+	}
+
+	writeMessage(writer, http.StatusCreated, "Successfully registered user")
+}
+
+/*
+resetPassword - GET /reset. Records a reset password email for the user under the email query parameter
+*/
+func (server *Server) resetPassword(writer http.ResponseWriter, request *http.Request) {
+	email := request.URL.Query().Get("email")
+	if _, err := mail.ParseAddress(email); err != nil {
+		writeError(writer, http.StatusBadRequest, sdkErrors.ErrInvalidEmail)
+		return
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	record, ok := server.users[email]
+	if !ok {
+		writeError(writer, http.StatusNotFound, sdkErrors.ErrNoUser)
+		return
+	}
+
+	record.resets++
+
+	writeMessage(writer, http.StatusOK, "Successfully sent reset password email")
+}
@@ -0,0 +1,299 @@
+package testserver
+
+import (
+	cardModel "github.com/stevezaluk/mtgjson-models/card"
+	sdkErrors "github.com/stevezaluk/mtgjson-models/errors"
+	"github.com/stevezaluk/mtgjson-sdk-client/card"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+uuidPattern - Matches the format of an MTGJSONv4 ID
+*/
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+/*
+cardRecord - A card held in the store of the server along with its owner
+*/
+type cardRecord struct {
+	// card - The stored card model
+	card *cardModel.CardSet
+
+	// owner - The email address of the user that owns the card
+	owner string
+}
+
+/*
+storeCard - Insert a card into the store, replacing its API metadata. Returns ErrCardMissingId if the card has no
+MTGJSONv4 ID and ErrCardAlreadyExist if a card already exists under the same ID
+*/
+func (server *Server) storeCard(card *cardModel.CardSet, owner string) error {
+	uuid := card.GetIdentifiers().GetMtgjsonV4Id()
+	if uuid == "" || card.GetName() == "" {
+		return sdkErrors.ErrCardMissingId
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	if _, ok := server.cards[uuid]; ok {
+		return sdkErrors.ErrCardAlreadyExist
+	}
+
+	card.MtgjsonApiMeta = newMeta(owner)
+	server.cards[uuid] = &cardRecord{card: card, owner: owner}
+
+	return nil
+}
+
+/*
+lookupCard - Returns a copy of the card stored under the MTGJSONv4 ID passed in the parameter that is visible to the
+owner. The caller must hold the mutex
+*/
+func (server *Server) lookupCard(uuid string, owner string) (*cardModel.CardSet, bool) {
+	record, ok := server.cards[uuid]
+	if !ok || !ownedBy(record.owner, owner) {
+		return nil, false
+	}
+
+	return clone(record.card), true
+}
+
+/*
+getCard - GET /card. Returns the card under the cardId query parameter, or every card if it is not set
+*/
+func (server *Server) getCard(writer http.ResponseWriter, request *http.Request) {
+	uuid := request.URL.Query().Get("cardId")
+	owner := request.URL.Query().Get("owner")
+
+	if uuid == "" {
+		server.indexCards(writer, request)
+		return
+	}
+
+	if !uuidPattern.MatchString(uuid) {
+		writeError(writer, http.StatusBadRequest, sdkErrors.ErrInvalidUUID)
+		return
+	}
+
+	server.mutex.RLock()
+	card, ok := server.lookupCard(uuid, owner)
+	server.mutex.RUnlock()
+
+	if !ok {
+		writeError(writer, http.StatusNotFound, sdkErrors.ErrNoCard)
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, card)
+}
+
+/*
+indexCards - GET /card without a card ID. Returns the page of cards visible to the owner selected by the limit and
+offset query parameters, ordered by MTGJSONv4 ID. Only the cards that match the search parameters sent by
+CardAPI.Search are paged through, see cardQuery
+*/
+func (server *Server) indexCards(writer http.ResponseWriter, request *http.Request) {
+	owner := request.URL.Query().Get("owner")
+
+	limit, offset, ok := pageParams(request)
+	if !ok {
+		writeError(writer, http.StatusBadRequest, sdkErrors.ErrNoCards)
+		return
+	}
+
+	query, ok := cardQuery(request.URL.Query())
+	if !ok {
+		writeError(writer, http.StatusBadRequest, sdkErrors.ErrNoCards)
+		return
+	}
+
+	server.mutex.RLock()
+	cards := make([]*cardModel.CardSet, 0, len(server.cards))
+	for uuid := range server.cards {
+		if card, ok := server.lookupCard(uuid, owner); ok && query.Matches(card) {
+			cards = append(cards, card)
+		}
+	}
+	server.mutex.RUnlock()
+
+	sort.Slice(cards, func(i, j int) bool {
+		return cards[i].GetIdentifiers().GetMtgjsonV4Id() < cards[j].GetIdentifiers().GetMtgjsonV4Id()
+	})
+
+	cards = page(cards, limit, offset)
+	if len(cards) == 0 {
+		writeError(writer, http.StatusNotFound, sdkErrors.ErrNoCards)
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, cards)
+}
+
+/*
+newCard - POST /card. Inserts the card in the request body under the owner query parameter
+*/
+func (server *Server) newCard(writer http.ResponseWriter, request *http.Request) {
+	card, err := decodeBody[cardModel.CardSet](request)
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, sdkErrors.ErrCardMissingId)
+		return
+	}
+
+	err = server.storeCard(card, ownerOrSystem(request.URL.Query().Get("owner")))
+	if err != nil {
+		writeError(writer, errorStatus(err), err)
+		return
+	}
+
+	writeMessage(writer, http.StatusCreated, "Successfully inserted new card")
+}
+
+/*
+putCard - PUT /card. Replaces the card under the cardId query parameter with the card in the request body
+*/
+func (server *Server) putCard(writer http.ResponseWriter, request *http.Request) {
+	server.replaceCard(writer, request, func(current *cardModel.CardSet) (*cardModel.CardSet, error) {
+		return decodeBody[cardModel.CardSet](request)
+	})
+}
+
+/*
+patchCard - PATCH /card. Applies the merge patch in the request body to the card under the cardId query parameter
+*/
+func (server *Server) patchCard(writer http.ResponseWriter, request *http.Request) {
+	server.replaceCard(writer, request, func(current *cardModel.CardSet) (*cardModel.CardSet, error) {
+		return mergePatch(current, request)
+	})
+}
+
+/*
+replaceCard - Shared implementation of PUT and PATCH /card. The build function is called with a copy of the stored
+card, without its API metadata, and returns the card that replaces it. The MTGJSONv4 ID of the card cannot be changed
+*/
+func (server *Server) replaceCard(writer http.ResponseWriter, request *http.Request, build func(current *cardModel.CardSet) (*cardModel.CardSet, error)) {
+	uuid := request.URL.Query().Get("cardId")
+	owner := request.URL.Query().Get("owner")
+
+	if !uuidPattern.MatchString(uuid) {
+		writeError(writer, http.StatusBadRequest, sdkErrors.ErrInvalidUUID)
+		return
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	record, ok := server.cards[uuid]
+	if !ok || !ownedBy(record.owner, owner) {
+		writeError(writer, http.StatusNotFound, sdkErrors.ErrNoCard)
+		return
+	}
+
+	if preconditionFailed(writer, request, record.card.GetMtgjsonApiMeta()) {
+		return
+	}
+
+	current := clone(record.card)
+	current.MtgjsonApiMeta = nil
+
+	card, err := build(current)
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	if card.GetMtgjsonApiMeta() != nil {
+		writeError(writer, http.StatusBadRequest, sdkErrors.ErrMetaApiMustBeNull)
+		return
+	}
+
+	if card.GetIdentifiers().GetMtgjsonV4Id() != uuid || card.GetName() == "" {
+		writeError(writer, http.StatusBadRequest, sdkErrors.ErrCardMissingId)
+		return
+	}
+
+	card.MtgjsonApiMeta = touchMeta(record.card.GetMtgjsonApiMeta())
+	record.card = card
+
+	writeMessage(writer, http.StatusOK, "Successfully updated card")
+}
+
+/*
+deleteCard - DELETE /card. Removes the card under the cardId query parameter
+*/
+func (server *Server) deleteCard(writer http.ResponseWriter, request *http.Request) {
+	uuid := request.URL.Query().Get("cardId")
+	owner := request.URL.Query().Get("owner")
+
+	if uuid == "" {
+		writeError(writer, http.StatusBadRequest, sdkErrors.ErrCardMissingId)
+		return
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	if _, ok := server.lookupCard(uuid, owner); !ok {
+		writeError(writer, http.StatusNotFound, sdkErrors.ErrNoCard)
+		return
+	}
+
+	delete(server.cards, uuid)
+
+	writeMessage(writer, http.StatusOK, "Successfully deleted card")
+}
+
+/*
+cardQuery - Parse the search parameters produced by card.CardQuery.Params back into a query. Returns false if a mana
+value is not a number
+*/
+func cardQuery(values url.Values) (*card.CardQuery, bool) {
+	query := card.NewQuery().
+		Name(values.Get("name")).
+		NameContains(values.Get("nameContains")).
+		Types(splitList(values.Get("types"))...).
+		Subtypes(splitList(values.Get("subtypes"))...).
+		Supertypes(splitList(values.Get("supertypes"))...).
+		Rarity(values.Get("rarity")).
+		SetCode(values.Get("setCode")).
+		LegalIn(splitList(values.Get("legalIn"))...)
+
+	if values.Has("colors") { // an empty value selects colorless cards
+		query.Colors(splitList(values.Get("colors"))...)
+	}
+
+	if values.Has("colorIdentity") {
+		query.ColorIdentity(splitList(values.Get("colorIdentity"))...)
+	}
+
+	for key, set := range map[string]func(float64) *card.CardQuery{"minManaValue": query.MinManaValue, "maxManaValue": query.MaxManaValue} {
+		if !values.Has(key) {
+			continue
+		}
+
+		manaValue, err := strconv.ParseFloat(values.Get(key), 64)
+		if err != nil {
+			return nil, false
+		}
+
+		set(manaValue)
+	}
+
+	return query, true
+}
+
+/*
+splitList - Returns the values of a comma separated query parameter, or nil if it is empty
+*/
+func splitList(value string) []string {
+	if value == "" {
+		return nil
+	}
+
+	return strings.Split(value, ",")
+}
@@ -0,0 +1,322 @@
+package testserver
+
+import (
+	cardModel "github.com/stevezaluk/mtgjson-models/card"
+	deckModel "github.com/stevezaluk/mtgjson-models/deck"
+	sdkErrors "github.com/stevezaluk/mtgjson-models/errors"
+	"net/http"
+	"slices"
+)
+
+/*
+deckRecord - A deck held in the store of the server along with its owner
+*/
+type deckRecord struct {
+	// deck - The stored deck model. Its content IDs are kept up to date by POST and DELETE /deck/content
+	deck *deckModel.Deck
+
+	// owner - The email address of the user that owns the deck
+	owner string
+}
+
+/*
+storeDeck - Insert a deck into the store. The cards referenced by its content IDs must already exist
+*/
+func (server *Server) storeDeck(deck *deckModel.Deck, owner string) error {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	if err := server.validateDeck(deck); err != nil {
+		return err
+	}
+
+	if _, ok := server.decks[deck.GetCode()]; ok {
+		return sdkErrors.ErrDeckAlreadyExists
+	}
+
+	deck.MtgjsonApiMeta = newMeta(owner)
+	server.decks[deck.GetCode()] = &deckRecord{deck: deck, owner: owner}
+
+	return nil
+}
+
+/*
+validateDeck - Returns the error that the API responds with when the deck passed in the parameter is created or
+replaced. The caller must hold the mutex
+*/
+func (server *Server) validateDeck(deck *deckModel.Deck) error {
+	switch {
+	case deck.GetMtgjsonApiMeta() != nil:
+		return sdkErrors.ErrMetaApiMustBeNull
+	case deck.GetCode() == "" || deck.GetName() == "":
+		return sdkErrors.ErrDeckMissingId
+	case deck.GetContentIds() == nil:
+		return sdkErrors.ErrDeckMissingContentIds
+	case !server.cardsExist(deckContentIds(deck.GetContentIds())):
+		return sdkErrors.ErrInvalidCards
+	}
+
+	return nil
+}
+
+/*
+lookupDeck - Returns the record of the deck stored under the code passed in the parameter that is visible to the
+owner. The caller must hold the mutex
+*/
+func (server *Server) lookupDeck(code string, owner string) (*deckRecord, error) {
+	if code == "" {
+		return nil, sdkErrors.ErrDeckMissingId
+	}
+
+	record, ok := server.decks[code]
+	if !ok || !ownedBy(record.owner, owner) {
+		return nil, sdkErrors.ErrNoDeck
+	}
+
+	return record, nil
+}
+
+/*
+cardsExist - Returns true if a card is stored under each of the MTGJSONv4 IDs passed in the parameter. The caller must
+hold the mutex
+*/
+func (server *Server) cardsExist(uuids []string) bool {
+	for _, uuid := range uuids {
+		if _, ok := server.cards[uuid]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+/*
+resolveCards - Returns copies of the cards stored under the MTGJSONv4 IDs passed in the parameter, in the same order.
+Returns ErrInvalidCards if any of them do not exist. The caller must hold the mutex
+*/
+func (server *Server) resolveCards(uuids []string) ([]*cardModel.CardSet, error) {
+	cards := make([]*cardModel.CardSet, 0, len(uuids))
+
+	for _, uuid := range uuids {
+		record, ok := server.cards[uuid]
+		if !ok {
+			return nil, sdkErrors.ErrInvalidCards
+		}
+
+		cards = append(cards, clone(record.card))
+	}
+
+	return cards, nil
+}
+
+/*
+deckContentIds - Returns the MTGJSONv4 IDs of each board of the deck content IDs as a single slice
+*/
+func deckContentIds(contentIds *deckModel.DeckContentIds) []string {
+	return slices.Concat(contentIds.GetMainBoard(), contentIds.GetSideBoard(), contentIds.GetCommander())
+}
+
+/*
+getDeck - GET /deck. Returns the deck under the deckCode query parameter
+*/
+func (server *Server) getDeck(writer http.ResponseWriter, request *http.Request) {
+	server.mutex.RLock()
+	defer server.mutex.RUnlock()
+
+	record, err := server.lookupDeck(request.URL.Query().Get("deckCode"), request.URL.Query().Get("owner"))
+	if err != nil {
+		writeError(writer, errorStatus(err), err)
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, record.deck)
+}
+
+/*
+newDeck - POST /deck. Inserts the deck in the request body under the owner query parameter
+*/
+func (server *Server) newDeck(writer http.ResponseWriter, request *http.Request) {
+	deck, err := decodeBody[deckModel.Deck](request)
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, sdkErrors.ErrDeckMissingId)
+		return
+	}
+
+	err = server.storeDeck(deck, ownerOrSystem(request.URL.Query().Get("owner")))
+	if err != nil {
+		writeError(writer, errorStatus(err), err)
+		return
+	}
+
+	writeMessage(writer, http.StatusCreated, "Successfully inserted new deck")
+}
+
+/*
+putDeck - PUT /deck. Replaces the deck under the deckCode query parameter with the deck in the request body
+*/
+func (server *Server) putDeck(writer http.ResponseWriter, request *http.Request) {
+	server.replaceDeck(writer, request, func(current *deckModel.Deck) (*deckModel.Deck, error) {
+		return decodeBody[deckModel.Deck](request)
+	})
+}
+
+/*
+patchDeck - PATCH /deck. Applies the merge patch in the request body to the deck under the deckCode query parameter
+*/
+func (server *Server) patchDeck(writer http.ResponseWriter, request *http.Request) {
+	server.replaceDeck(writer, request, func(current *deckModel.Deck) (*deckModel.Deck, error) {
+		return mergePatch(current, request)
+	})
+}
+
+/*
+replaceDeck - Shared implementation of PUT and PATCH /deck. The build function is called with a copy of the stored
+deck, without its API metadata, and returns the deck that replaces it. The code of the deck cannot be changed
+*/
+func (server *Server) replaceDeck(writer http.ResponseWriter, request *http.Request, build func(current *deckModel.Deck) (*deckModel.Deck, error)) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	record, err := server.lookupDeck(request.URL.Query().Get("deckCode"), request.URL.Query().Get("owner"))
+	if err != nil {
+		writeError(writer, errorStatus(err), err)
+		return
+	}
+
+	if preconditionFailed(writer, request, record.deck.GetMtgjsonApiMeta()) {
+		return
+	}
+
+	current := clone(record.deck)
+	current.MtgjsonApiMeta = nil
+
+	deck, err := build(current)
+	if err == nil && deck.GetCode() != record.deck.GetCode() {
+		err = sdkErrors.ErrDeckMissingId
+	}
+
+	if err == nil {
+		err = server.validateDeck(deck)
+	}
+
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	deck.MtgjsonApiMeta = touchMeta(record.deck.GetMtgjsonApiMeta())
+	record.deck = deck
+
+	writeMessage(writer, http.StatusOK, "Successfully updated deck")
+}
+
+/*
+deleteDeck - DELETE /deck. Removes the deck under the deckCode query parameter
+*/
+func (server *Server) deleteDeck(writer http.ResponseWriter, request *http.Request) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	record, err := server.lookupDeck(request.URL.Query().Get("deckCode"), request.URL.Query().Get("owner"))
+	if err != nil {
+		writeError(writer, errorStatus(err), err)
+		return
+	}
+
+	delete(server.decks, record.deck.GetCode())
+
+	writeMessage(writer, http.StatusOK, "Successfully deleted deck")
+}
+
+/*
+getDeckContents - GET /deck/content. Returns the cards referenced by the content IDs of the deck under the deckCode
+query parameter
+*/
+func (server *Server) getDeckContents(writer http.ResponseWriter, request *http.Request) {
+	server.mutex.RLock()
+	defer server.mutex.RUnlock()
+
+	record, err := server.lookupDeck(request.URL.Query().Get("deckCode"), request.URL.Query().Get("owner"))
+	if err != nil {
+		writeError(writer, errorStatus(err), err)
+		return
+	}
+
+	contents := &deckModel.DeckContents{}
+	contentIds := record.deck.GetContentIds()
+
+	for _, board := range []struct {
+		uuids []string
+		cards *[]*cardModel.CardSet
+	}{
+		{contentIds.GetMainBoard(), &contents.MainBoard},
+		{contentIds.GetSideBoard(), &contents.SideBoard},
+		{contentIds.GetCommander(), &contents.Commander},
+	} {
+		if *board.cards, err = server.resolveCards(board.uuids); err != nil {
+			writeError(writer, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	writeJSON(writer, http.StatusOK, contents)
+}
+
+/*
+addDeckCards - POST /deck/content. Appends the content IDs in the request body to each board of the deck
+*/
+func (server *Server) addDeckCards(writer http.ResponseWriter, request *http.Request) {
+	server.updateDeckCards(writer, request, func(board []string, uuids []string) []string {
+		return append(board, uuids...)
+	})
+}
+
+/*
+removeDeckCards - DELETE /deck/content. Removes every instance of the content IDs in the request body from each board
+of the deck
+*/
+func (server *Server) removeDeckCards(writer http.ResponseWriter, request *http.Request) {
+	server.updateDeckCards(writer, request, func(board []string, uuids []string) []string {
+		return slices.DeleteFunc(board, func(uuid string) bool {
+			return slices.Contains(uuids, uuid)
+		})
+	})
+}
+
+/*
+updateDeckCards - Shared implementation of POST and DELETE /deck/content. The update function is called for each board
+with the current content IDs of the board and the content IDs from the request body
+*/
+func (server *Server) updateDeckCards(writer http.ResponseWriter, request *http.Request, update func(board []string, uuids []string) []string) {
+	cards, err := decodeBody[deckModel.DeckContentIds](request)
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	if len(deckContentIds(cards)) == 0 {
+		writeError(writer, http.StatusBadRequest, sdkErrors.ErrDeckNoCards)
+		return
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	record, err := server.lookupDeck(request.URL.Query().Get("deckCode"), request.URL.Query().Get("owner"))
+	if err != nil {
+		writeError(writer, errorStatus(err), err)
+		return
+	}
+
+	if !server.cardsExist(deckContentIds(cards)) {
+		writeError(writer, http.StatusBadRequest, sdkErrors.ErrInvalidCards)
+		return
+	}
+
+	contentIds := record.deck.ContentIds
+	contentIds.MainBoard = update(contentIds.MainBoard, cards.GetMainBoard())
+	contentIds.SideBoard = update(contentIds.SideBoard, cards.GetSideBoard())
+	contentIds.Commander = update(contentIds.Commander, cards.GetCommander())
+
+	writeMessage(writer, http.StatusOK, "Successfully updated deck contents")
+}
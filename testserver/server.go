@@ -0,0 +1,473 @@
+package testserver
+
+import (
+	"encoding/json"
+	"errors"
+	apiModels "github.com/stevezaluk/mtgjson-models/api"
+	cardModel "github.com/stevezaluk/mtgjson-models/card"
+	deckModel "github.com/stevezaluk/mtgjson-models/deck"
+	sdkErrors "github.com/stevezaluk/mtgjson-models/errors"
+	metaModel "github.com/stevezaluk/mtgjson-models/meta"
+	setModel "github.com/stevezaluk/mtgjson-models/set"
+	"github.com/stevezaluk/mtgjson-sdk-client/client"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+SystemOwner - The owner that objects are assigned to when they are created without an owner, mirroring the system
+user of the MTGJSON API
+*/
+const SystemOwner = "system"
+
+/*
+DefaultTokenLifetime - The lifetime of the access tokens issued by the server unless WithTokenLifetime is used
+*/
+const DefaultTokenLifetime = time.Hour
+
+/*
+Server - An in-memory fake of the MTGJSON API built on httptest.Server. It implements the /card, /deck,
+/deck/content, /set, /set/content, /login, /register, /refresh, /reset and /user endpoints with the same
+status codes and APIResponse errors as the real API, so that api.New or api.NewFromURL can be pointed at it
+in unit tests without a live server or MongoDB behind it. Stored cards, decks and sets carry API metadata
+whose modified date is checked against the If-Match header of PUT and PATCH requests. The /refresh endpoint and the
+PUT and PATCH routes are not provided by the upstream API yet; they are implemented so that token refresh and the
+Update and Patch methods of the client can be exercised
+*/
+type Server struct {
+	// server - The underlying httptest.Server the handlers are served from
+	server *httptest.Server
+
+	// requireAuth - If true, the card, deck, set and user endpoints reject requests without a valid access token
+	requireAuth bool
+
+	// tokenLifetime - The lifetime of the access tokens issued by /login and /refresh
+	tokenLifetime time.Duration
+
+	// now - Returns the current time, against which the expiry of access tokens is checked
+	now func() time.Time
+
+	// cards - The stored cards keyed by their MTGJSONv4 ID
+	cards map[string]*cardRecord
+
+	// decks - The stored decks keyed by their deck code
+	decks map[string]*deckRecord
+
+	// sets - The stored sets keyed by their set code
+	sets map[string]*setRecord
+
+	// users - The registered users keyed by their email address
+	users map[string]*userRecord
+
+	// accessTokens - The issued access tokens mapped to the email address of the user they were issued to
+	accessTokens map[string]issuedToken
+
+	// refreshTokens - The issued refresh tokens mapped to the email address of the user they were issued to
+	refreshTokens map[string]string
+
+	// mutex - Guards each of the stores above
+	mutex sync.RWMutex
+}
+
+/*
+Option - Configures a Server when it is constructed with New
+*/
+type Option func(server *Server)
+
+/*
+WithoutAuth - Serve the card, deck, set and user endpoints without requiring an access token
+*/
+func WithoutAuth() Option {
+	return func(server *Server) {
+		server.requireAuth = false
+	}
+}
+
+/*
+WithTokenLifetime - Set the lifetime of the access tokens issued by /login and /refresh. Short lifetimes can be used
+for exercising the token refresh of the client.HTTPClient, which must first be enabled with
+MtgjsonAPI.EnableTokenRefresh
+*/
+func WithTokenLifetime(lifetime time.Duration) Option {
+	return func(server *Server) {
+		server.tokenLifetime = lifetime
+	}
+}
+
+/*
+WithClock - Set the function the server uses for the current time when issuing access tokens and checking their
+expiry. A clock that runs ahead of the real time can be used for expiring tokens without waiting for them to expire
+*/
+func WithClock(now func() time.Time) Option {
+	return func(server *Server) {
+		server.now = now
+	}
+}
+
+/*
+New - Create and start a new Server with empty stores. The server must be closed with Close once the test has
+finished
+*/
+func New(opts ...Option) *Server {
+	server := &Server{
+		requireAuth:   true,
+		tokenLifetime: DefaultTokenLifetime,
+		now:           time.Now,
+		cards:         make(map[string]*cardRecord),
+		decks:         make(map[string]*deckRecord),
+		sets:          make(map[string]*setRecord),
+		users:         make(map[string]*userRecord),
+		accessTokens:  make(map[string]issuedToken),
+		refreshTokens: make(map[string]string),
+	}
+
+	for _, opt := range opts {
+		opt(server)
+	}
+
+	server.server = httptest.NewServer(server.routes())
+
+	return server
+}
+
+/*
+URL - Returns the base URL of the server, to be passed to api.NewFromURL
+*/
+func (server *Server) URL() string {
+	return server.server.URL
+}
+
+/*
+Close - Shut down the server and block until all outstanding requests have completed
+*/
+func (server *Server) Close() {
+	server.server.Close()
+}
+
+/*
+routes - Register the handler of each endpoint on a new http.ServeMux
+*/
+func (server *Server) routes() *http.ServeMux {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /card", server.authenticated(server.getCard))
+	mux.HandleFunc("POST /card", server.authenticated(server.newCard))
+	mux.HandleFunc("PUT /card", server.authenticated(server.putCard))
+	mux.HandleFunc("PATCH /card", server.authenticated(server.patchCard))
+	mux.HandleFunc("DELETE /card", server.authenticated(server.deleteCard))
+
+	mux.HandleFunc("GET /deck", server.authenticated(server.getDeck))
+	mux.HandleFunc("POST /deck", server.authenticated(server.newDeck))
+	mux.HandleFunc("PUT /deck", server.authenticated(server.putDeck))
+	mux.HandleFunc("PATCH /deck", server.authenticated(server.patchDeck))
+	mux.HandleFunc("DELETE /deck", server.authenticated(server.deleteDeck))
+	mux.HandleFunc("GET /deck/content", server.authenticated(server.getDeckContents))
+	mux.HandleFunc("POST /deck/content", server.authenticated(server.addDeckCards))
+	mux.HandleFunc("DELETE /deck/content", server.authenticated(server.removeDeckCards))
+
+	mux.HandleFunc("GET /set", server.authenticated(server.getSet))
+	mux.HandleFunc("POST /set", server.authenticated(server.newSet))
+	mux.HandleFunc("PUT /set", server.authenticated(server.putSet))
+	mux.HandleFunc("PATCH /set", server.authenticated(server.patchSet))
+	mux.HandleFunc("DELETE /set", server.authenticated(server.deleteSet))
+	mux.HandleFunc("GET /set/content", server.authenticated(server.getSetContents))
+	mux.HandleFunc("POST /set/content", server.authenticated(server.addSetCards))
+	mux.HandleFunc("DELETE /set/content", server.authenticated(server.removeSetCards))
+
+	mux.HandleFunc("POST /login", server.login)
+	mux.HandleFunc("POST /register", server.register)
+	mux.HandleFunc("POST /refresh", server.refresh)
+	mux.HandleFunc("GET /reset", server.resetPassword)
+
+	mux.HandleFunc("GET /user", server.authenticated(server.getUser))
+	mux.HandleFunc("DELETE /user", server.authenticated(server.deactivateUser))
+
+	return mux
+}
+
+/*
+authenticated - Wrap a handler so that it responds with a 401 and ErrTokenInvalid when the request does not carry a
+valid access token
+*/
+func (server *Server) authenticated(handler http.HandlerFunc) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if !server.requireAuth {
+			handler(writer, request)
+			return
+		}
+
+		token, ok := strings.CutPrefix(request.Header.Get("Authorization"), "Bearer ")
+		if !ok || server.tokenOwner(token) == "" {
+			writeError(writer, http.StatusUnauthorized, sdkErrors.ErrTokenInvalid)
+			return
+		}
+
+		handler(writer, request)
+	}
+}
+
+/*
+ownedBy - Returns true if an object owned by the owner passed in the first parameter is visible to a request scoped to
+the owner passed in the second parameter. An empty scope does not filter by owner
+*/
+func ownedBy(owner string, scope string) bool {
+	return scope == "" || owner == scope
+}
+
+/*
+pageParams - Parse the limit and offset query parameters of the request. Parameters that are not set are returned as
+0. Returns false if either of them is not a non-negative integer
+*/
+func pageParams(request *http.Request) (int, int, bool) {
+	values := [2]int{}
+
+	for index, key := range []string{"limit", "offset"} {
+		raw := request.URL.Query().Get(key)
+		if raw == "" {
+			continue
+		}
+
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 0 {
+			return 0, 0, false
+		}
+
+		values[index] = value
+	}
+
+	return values[0], values[1], true
+}
+
+/*
+page - Returns the slice of items that a limit and offset select. A limit of 0 selects every item after the offset
+*/
+func page[T any](items []T, limit int, offset int) []T {
+	items = items[min(offset, len(items)):]
+
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+
+	return items
+}
+
+/*
+decodeBody - Decode the JSON body of the request into a new instance of T. Returns ErrInvalidObjectStructure if the
+body is not valid JSON
+*/
+func decodeBody[T any](request *http.Request) (*T, error) {
+	value := new(T)
+
+	if err := json.NewDecoder(request.Body).Decode(value); err != nil {
+		return nil, sdkErrors.ErrInvalidObjectStructure
+	}
+
+	return value, nil
+}
+
+/*
+clone - Returns a deep copy of the model passed in the parameter, so that callers can never modify the objects held in
+the stores of the server
+*/
+func clone[T any](value *T) *T {
+	data, err := json.Marshal(value)
+	if err != nil {
+		panic(err) // models are always serializable, so this indicates a programming error
+	}
+
+	copied := new(T)
+	if err := json.Unmarshal(data, copied); err != nil {
+		panic(err)
+	}
+
+	return copied
+}
+
+/*
+mergePatch - Apply the JSON merge patch (RFC 7386) in the body of the request to the model passed in the parameter,
+returning the result as a new instance of T. Returns ErrInvalidObjectStructure if the body is not a JSON object or the
+patched model cannot be decoded
+*/
+func mergePatch[T any](current *T, request *http.Request) (*T, error) {
+	patch, err := decodeBody[map[string]any](request)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(current)
+	if err != nil {
+		return nil, err
+	}
+
+	document := make(map[string]any)
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	data, err = json.Marshal(applyMergePatch(document, *patch))
+	if err != nil {
+		return nil, err
+	}
+
+	patched := new(T)
+	if err := json.Unmarshal(data, patched); err != nil {
+		return nil, sdkErrors.ErrInvalidObjectStructure
+	}
+
+	return patched, nil
+}
+
+/*
+applyMergePatch - Returns the result of applying a decoded merge patch to a decoded JSON value, as described by
+RFC 7386. Null members of the patch remove the member from the target
+*/
+func applyMergePatch(target any, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = make(map[string]any)
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+
+		targetObject[key] = applyMergePatch(targetObject[key], value)
+	}
+
+	return targetObject
+}
+
+/*
+newMeta - Returns the API metadata of a model that is being created by the owner passed in the parameter
+*/
+func newMeta(owner string) *metaModel.MTGJSONAPIMeta {
+	now := timestamp()
+
+	return &metaModel.MTGJSONAPIMeta{Owner: owner, CreationDate: now, ModifiedDate: now}
+}
+
+/*
+touchMeta - Returns a copy of the API metadata passed in the parameter with its modified date set to the current time
+*/
+func touchMeta(meta *metaModel.MTGJSONAPIMeta) *metaModel.MTGJSONAPIMeta {
+	return &metaModel.MTGJSONAPIMeta{Owner: meta.GetOwner(), CreationDate: meta.GetCreationDate(), ModifiedDate: timestamp()}
+}
+
+/*
+timestamp - Returns the current time in the format of the dates held in the API metadata
+*/
+func timestamp() string {
+	return time.Now().UTC().Format(time.RFC3339Nano)
+}
+
+/*
+preconditionFailed - Returns true and responds with a 412 and ErrConflict if the request carries an If-Match header
+that does not equal the modified date of the stored model
+*/
+func preconditionFailed(writer http.ResponseWriter, request *http.Request, meta *metaModel.MTGJSONAPIMeta) bool {
+	modifiedDate := request.Header.Get(client.IfMatchHeader)
+	if modifiedDate == "" || modifiedDate == meta.GetModifiedDate() {
+		return false
+	}
+
+	writeError(writer, http.StatusPreconditionFailed, client.ErrConflict)
+
+	return true
+}
+
+/*
+writeJSON - Write the value passed in the parameter as a JSON response with the status code passed in the parameter
+*/
+func writeJSON(writer http.ResponseWriter, statusCode int, value any) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(statusCode)
+
+	_ = json.NewEncoder(writer).Encode(value)
+}
+
+/*
+writeMessage - Write an APIResponse carrying only a message
+*/
+func writeMessage(writer http.ResponseWriter, statusCode int, message string) {
+	writeJSON(writer, statusCode, &apiModels.APIResponse{Message: message})
+}
+
+/*
+writeError - Write an APIResponse whose Err field carries the message of the sentinel error passed in the parameter,
+which is what client.ErrorMap matches against
+*/
+func writeError(writer http.ResponseWriter, statusCode int, err error) {
+	writeJSON(writer, statusCode, &apiModels.APIResponse{Err: err.Error()})
+}
+
+/*
+errorStatus - Returns the status code the server responds with for the sentinel error passed in the parameter
+*/
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, sdkErrors.ErrNoCard), errors.Is(err, sdkErrors.ErrNoCards),
+		errors.Is(err, sdkErrors.ErrNoDeck), errors.Is(err, sdkErrors.ErrNoSet),
+		errors.Is(err, sdkErrors.ErrNoSets), errors.Is(err, sdkErrors.ErrNoUser):
+		return http.StatusNotFound
+	case errors.Is(err, sdkErrors.ErrCardAlreadyExist), errors.Is(err, sdkErrors.ErrDeckAlreadyExists),
+		errors.Is(err, sdkErrors.ErrSetAlreadyExists), errors.Is(err, sdkErrors.ErrUserAlreadyExist):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+/*
+AddCard - Store a card owned by the owner passed in the parameter, without going through POST /card. The card is
+copied, so modifying it afterward has no effect on the server. Returns ErrCardMissingId if the card has no
+MTGJSONv4 ID and ErrCardAlreadyExist if a card already exists under the same ID
+*/
+func (server *Server) AddCard(card *cardModel.CardSet, owner string) error {
+	return server.storeCard(clone(card), ownerOrSystem(owner))
+}
+
+/*
+AddDeck - Store a deck owned by the owner passed in the parameter. Returns the same errors as POST /deck
+*/
+func (server *Server) AddDeck(deck *deckModel.Deck, owner string) error {
+	return server.storeDeck(clone(deck), ownerOrSystem(owner))
+}
+
+/*
+AddSet - Store a set owned by the owner passed in the parameter, containing the cards whose MTGJSONv4 IDs are passed in
+the parameter. Returns the same errors as POST /set
+*/
+func (server *Server) AddSet(set *setModel.Set, owner string, cards ...string) error {
+	err := server.storeSet(clone(set), ownerOrSystem(owner))
+	if err != nil {
+		return err
+	}
+
+	if len(cards) == 0 {
+		return nil
+	}
+
+	return server.addToSet(set.GetCode(), "", cards)
+}
+
+/*
+ownerOrSystem - Returns SystemOwner if the owner passed in the parameter is empty
+*/
+func ownerOrSystem(owner string) string {
+	if owner == "" {
+		return SystemOwner
+	}
+
+	return owner
+}
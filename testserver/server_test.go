@@ -0,0 +1,538 @@
+package testserver_test
+
+import (
+	"context"
+	"errors"
+	cardModel "github.com/stevezaluk/mtgjson-models/card"
+	deckModel "github.com/stevezaluk/mtgjson-models/deck"
+	sdkErrors "github.com/stevezaluk/mtgjson-models/errors"
+	setModel "github.com/stevezaluk/mtgjson-models/set"
+	"github.com/stevezaluk/mtgjson-sdk-client/api"
+	"github.com/stevezaluk/mtgjson-sdk-client/client"
+	"github.com/stevezaluk/mtgjson-sdk-client/testserver"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const (
+	lightningBolt = "00000000-0000-0000-0000-000000000001"
+	counterspell  = "00000000-0000-0000-0000-000000000002"
+	giantGrowth   = "00000000-0000-0000-0000-000000000003"
+)
+
+/*
+newAPI - Start a testserver with the options passed in the parameter and return an MtgjsonAPI that targets it
+*/
+func newAPI(t *testing.T, opts ...testserver.Option) (*testserver.Server, *api.MtgjsonAPI) {
+	server := testserver.New(opts...)
+	t.Cleanup(server.Close)
+
+	mtgjson, err := api.NewFromURL(server.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return server, mtgjson
+}
+
+/*
+newCard - Returns a card model with the name and MTGJSONv4 ID passed in the parameter
+*/
+func newCard(uuid string, name string) *cardModel.CardSet {
+	return &cardModel.CardSet{Name: name, Identifiers: &cardModel.CardIdentifiers{MtgjsonV4Id: uuid}}
+}
+
+/*
+seedCards - Store a card under each of the IDs used by the tests
+*/
+func seedCards(t *testing.T, server *testserver.Server) {
+	for uuid, name := range map[string]string{lightningBolt: "Lightning Bolt", counterspell: "Counterspell", giantGrowth: "Giant Growth"} {
+		if err := server.AddCard(newCard(uuid, name), ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAuth(t *testing.T) {
+	ctx := context.Background()
+	server, mtgjson := newAPI(t)
+
+	if _, err := mtgjson.User.GetUser(ctx, "user@example.com"); !errors.Is(err, sdkErrors.ErrTokenInvalid) {
+		t.Fatalf("GetUser() without a token error = %v, want ErrTokenInvalid", err)
+	}
+
+	if _, err := mtgjson.Auth.RegisterUser(ctx, "user@example.com", "user", "password123"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		call    func() error
+		wantErr error
+	}{
+		{
+			name: "register an existing user",
+			call: func() error {
+				_, err := mtgjson.Auth.RegisterUser(ctx, "user@example.com", "user", "password123")
+				return err
+			},
+			wantErr: sdkErrors.ErrUserAlreadyExist,
+		},
+		{
+			name: "register with a short password",
+			call: func() error {
+				_, err := mtgjson.Auth.RegisterUser(ctx, "other@example.com", "other", "short")
+				return err
+			},
+			wantErr: sdkErrors.ErrInvalidPasswordLength,
+		},
+		{
+			name: "login with the wrong password",
+			call: func() error {
+				_, err := mtgjson.Auth.Login(ctx, "user@example.com", "wrong-password")
+				return err
+			},
+			wantErr: sdkErrors.ErrInvalidPermissions,
+		},
+		{
+			name: "login as an unknown user",
+			call: func() error {
+				_, err := mtgjson.Auth.Login(ctx, "unknown@example.com", "password123")
+				return err
+			},
+			wantErr: sdkErrors.ErrNoUser,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.call(); !errors.Is(err, test.wantErr) {
+				t.Errorf("error = %v, want %v", err, test.wantErr)
+			}
+		})
+	}
+
+	if err := mtgjson.SetEmailPasswordAuth(ctx, "user@example.com", "password123"); err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := mtgjson.User.GetUser(ctx, "user@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if user.GetUsername() != "user" {
+		t.Errorf("username = %q, want user", user.GetUsername())
+	}
+
+	if _, err := mtgjson.Auth.ResetUserPassword(ctx, "user@example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	if resets := server.PasswordResets("user@example.com"); resets != 1 {
+		t.Errorf("password resets = %d, want 1", resets)
+	}
+
+	if _, err := mtgjson.User.DeactivateUser(ctx, "user@example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := mtgjson.User.GetUser(ctx, "user@example.com"); !errors.Is(err, sdkErrors.ErrTokenInvalid) {
+		t.Errorf("GetUser() after deactivation error = %v, want ErrTokenInvalid", err)
+	}
+}
+
+func TestTokenRefresh(t *testing.T) {
+	tests := []struct {
+		name    string
+		refresh bool
+		wantErr error
+	}{
+		{name: "enabled", refresh: true},
+		{name: "disabled", wantErr: sdkErrors.ErrTokenInvalid},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			var elapsed atomic.Int64
+			server, mtgjson := newAPI(t, testserver.WithClock(func() time.Time {
+				return time.Now().Add(time.Duration(elapsed.Load()))
+			}))
+
+			if err := server.AddUser("user@example.com", "user", "password123"); err != nil {
+				t.Fatal(err)
+			}
+
+			if test.refresh {
+				mtgjson.EnableTokenRefresh()
+			}
+
+			if err := mtgjson.SetEmailPasswordAuth(ctx, "user@example.com", "password123"); err != nil {
+				t.Fatal(err)
+			}
+
+			issued := mtgjson.Client().BearerToken().AccessToken
+			elapsed.Store(int64(2 * testserver.DefaultTokenLifetime)) // the token only expires on the server, so it is rejected with a 401
+
+			_, err := mtgjson.User.GetUser(ctx, "user@example.com")
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("GetUser() after expiry error = %v, want %v", err, test.wantErr)
+			}
+
+			if test.refresh && mtgjson.Client().BearerToken().AccessToken == issued {
+				t.Error("the access token was not replaced by a refresh")
+			}
+		})
+	}
+}
+
+func TestCard(t *testing.T) {
+	ctx := context.Background()
+	server, mtgjson := newAPI(t, testserver.WithoutAuth())
+	seedCards(t, server)
+
+	card, err := mtgjson.Card.GetCard(ctx, lightningBolt, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if card.GetName() != "Lightning Bolt" {
+		t.Errorf("GetCard() name = %q, want Lightning Bolt", card.GetName())
+	}
+
+	errorTests := []struct {
+		name    string
+		call    func() error
+		wantErr error
+	}{
+		{
+			name: "get an unknown card",
+			call: func() error {
+				_, err := mtgjson.Card.GetCard(ctx, "00000000-0000-0000-0000-000000000099", "")
+				return err
+			},
+			wantErr: sdkErrors.ErrNoCard,
+		},
+		{
+			name: "get an invalid ID",
+			call: func() error {
+				_, err := mtgjson.Card.GetCard(ctx, "not-a-uuid", "")
+				return err
+			},
+			wantErr: sdkErrors.ErrInvalidUUID,
+		},
+		{
+			name: "get a card of another owner",
+			call: func() error {
+				_, err := mtgjson.Card.GetCard(ctx, lightningBolt, "user@example.com")
+				return err
+			},
+			wantErr: sdkErrors.ErrNoCard,
+		},
+		{
+			name: "create an existing card",
+			call: func() error {
+				_, err := mtgjson.Card.NewCard(ctx, newCard(lightningBolt, "Lightning Bolt"), "")
+				return err
+			},
+			wantErr: sdkErrors.ErrCardAlreadyExist,
+		},
+		{
+			name: "index past the last card",
+			call: func() error {
+				_, err := mtgjson.Card.IndexCards(ctx, 10, 100)
+				return err
+			},
+			wantErr: sdkErrors.ErrNoCards,
+		},
+		{
+			name: "update a nil card",
+			call: func() error {
+				_, err := mtgjson.Card.UpdateCard(ctx, nil, "")
+				return err
+			},
+			wantErr: sdkErrors.ErrCardMissingId,
+		},
+		{
+			name: "patch a nil card",
+			call: func() error {
+				_, err := mtgjson.Card.PatchCard(ctx, card, nil, "")
+				return err
+			},
+			wantErr: sdkErrors.ErrCardMissingId,
+		},
+		{
+			name: "upsert a card without an ID",
+			call: func() error {
+				_, err := mtgjson.Card.UpsertCard(ctx, &cardModel.CardSet{Name: "Lightning Bolt"}, "")
+				return err
+			},
+			wantErr: sdkErrors.ErrCardMissingId,
+		},
+	}
+
+	for _, test := range errorTests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.call(); !errors.Is(err, test.wantErr) {
+				t.Errorf("error = %v, want %v", err, test.wantErr)
+			}
+		})
+	}
+
+	cards, missing, err := mtgjson.Card.GetCards(ctx, []string{counterspell, "00000000-0000-0000-0000-000000000099", "", counterspell}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(cards) != 1 || len(missing) != 2 {
+		t.Errorf("GetCards() = %d cards and errors %v, want 1 card and 2 errors", len(cards), missing)
+	}
+
+	if !errors.Is(missing["00000000-0000-0000-0000-000000000099"], sdkErrors.ErrNoCard) || !errors.Is(missing[""], sdkErrors.ErrInvalidUUID) {
+		t.Errorf("GetCards() errors = %v, want ErrNoCard for the unknown ID and ErrInvalidUUID for the empty one", missing)
+	}
+
+	var iterated, streamed []string
+	for card, err := range mtgjson.Card.IterCards(ctx, 2) {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		iterated = append(iterated, card.GetName())
+	}
+
+	for card, err := range mtgjson.Card.StreamCards(ctx, 10, 0) {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		streamed = append(streamed, card.GetName())
+	}
+
+	if len(iterated) != 3 || !slices.Equal(iterated, streamed) {
+		t.Errorf("IterCards() = %v and StreamCards() = %v, want the same 3 cards", iterated, streamed)
+	}
+
+	updated := newCard(lightningBolt, "Lightning Bolt")
+	updated.Text = "Lightning Bolt deals 3 damage to any target."
+	if _, err := mtgjson.Card.PatchCard(ctx, card, updated, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := mtgjson.Card.UpdateCard(ctx, card, ""); !errors.Is(err, client.ErrConflict) {
+		t.Errorf("UpdateCard() with a stale card error = %v, want ErrConflict", err)
+	}
+
+	if _, err := mtgjson.Card.DeleteCard(ctx, lightningBolt, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := mtgjson.Card.GetCard(ctx, lightningBolt, ""); !errors.Is(err, sdkErrors.ErrNoCard) {
+		t.Errorf("GetCard() after DeleteCard() error = %v, want ErrNoCard", err)
+	}
+}
+
+func TestDeck(t *testing.T) {
+	ctx := context.Background()
+	server, mtgjson := newAPI(t, testserver.WithoutAuth())
+	seedCards(t, server)
+
+	deck := &deckModel.Deck{
+		Code:       "BURN",
+		Name:       "Burn",
+		ContentIds: &deckModel.DeckContentIds{MainBoard: []string{lightningBolt, lightningBolt}},
+	}
+
+	if _, err := mtgjson.Deck.NewDeck(ctx, deck, "user@example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	errorTests := []struct {
+		name    string
+		deck    *deckModel.Deck
+		wantErr error
+	}{
+		{name: "existing code", deck: deck, wantErr: sdkErrors.ErrDeckAlreadyExists},
+		{name: "no code", deck: &deckModel.Deck{ContentIds: deck.ContentIds}, wantErr: sdkErrors.ErrDeckMissingId},
+		{name: "no contents", deck: &deckModel.Deck{Code: "EMPTY", Name: "Empty"}, wantErr: sdkErrors.ErrDeckMissingContentIds},
+		{
+			name:    "unknown cards",
+			deck:    &deckModel.Deck{Code: "UNKNOWN", Name: "Unknown", ContentIds: &deckModel.DeckContentIds{MainBoard: []string{"00000000-0000-0000-0000-000000000099"}}},
+			wantErr: sdkErrors.ErrInvalidCards,
+		},
+	}
+
+	for _, test := range errorTests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := mtgjson.Deck.NewDeck(ctx, test.deck, "user@example.com"); !errors.Is(err, test.wantErr) {
+				t.Errorf("NewDeck() error = %v, want %v", err, test.wantErr)
+			}
+		})
+	}
+
+	if _, err := mtgjson.Deck.UpsertDeck(ctx, nil, "user@example.com"); !errors.Is(err, sdkErrors.ErrDeckMissingId) {
+		t.Errorf("UpsertDeck() with a nil deck error = %v, want ErrDeckMissingId", err)
+	}
+
+	if _, err := mtgjson.Deck.UpsertDeck(ctx, &deckModel.Deck{Name: "Burn"}, "user@example.com"); !errors.Is(err, sdkErrors.ErrDeckMissingId) {
+		t.Errorf("UpsertDeck() with a deck without a code error = %v, want ErrDeckMissingId", err)
+	}
+
+	if _, err := mtgjson.Deck.GetDeck(ctx, "BURN", "other@example.com"); !errors.Is(err, sdkErrors.ErrNoDeck) {
+		t.Errorf("GetDeck() as another owner error = %v, want ErrNoDeck", err)
+	}
+
+	cards := &deckModel.DeckContentIds{SideBoard: []string{counterspell}}
+	if _, err := mtgjson.Deck.AddCards(ctx, "BURN", cards, "user@example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	contents, err := mtgjson.Deck.GetDeckContents(ctx, "BURN", "user@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(contents.GetMainBoard()) != 2 || len(contents.GetSideBoard()) != 1 {
+		t.Errorf("GetDeckContents() = %d main board and %d sideboard cards, want 2 and 1", len(contents.GetMainBoard()), len(contents.GetSideBoard()))
+	}
+
+	if _, err := mtgjson.Deck.RemoveCards(ctx, "BURN", cards, "user@example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := mtgjson.Deck.GetDeck(ctx, "BURN", "user@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stored.GetContentIds().GetSideBoard()) != 0 {
+		t.Errorf("sideboard after RemoveCards() = %v, want it empty", stored.GetContentIds().GetSideBoard())
+	}
+
+	desired := &deckModel.Deck{Code: "BURN", Name: "Mono Red Burn", ContentIds: stored.GetContentIds()}
+	for _, want := range []client.UpsertAction{client.UpsertUpdated, client.UpsertUnchanged} {
+		action, err := mtgjson.Deck.UpsertDeck(ctx, desired, "user@example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if action != want {
+			t.Errorf("UpsertDeck() = %s, want %s", action, want)
+		}
+	}
+
+	if _, err := mtgjson.Deck.DeleteDeck(ctx, "BURN", "user@example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := mtgjson.Deck.GetDeck(ctx, "BURN", ""); !errors.Is(err, sdkErrors.ErrNoDeck) {
+		t.Errorf("GetDeck() after DeleteDeck() error = %v, want ErrNoDeck", err)
+	}
+}
+
+func TestSet(t *testing.T) {
+	ctx := context.Background()
+	server, mtgjson := newAPI(t, testserver.WithoutAuth())
+	seedCards(t, server)
+
+	if err := server.AddSet(&setModel.Set{Code: "LEA", Name: "Alpha", ReleaseDate: "1993-08-05"}, "", lightningBolt); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []client.UpsertAction{client.UpsertUpdated, client.UpsertUnchanged} {
+		action, err := mtgjson.Set.UpsertSet(ctx, &setModel.Set{Code: "LEA", Name: "Limited Edition Alpha"}, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if action != want {
+			t.Errorf("UpsertSet() = %s, want %s", action, want)
+		}
+	}
+
+	alpha, err := mtgjson.Set.GetSet(ctx, "LEA", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if alpha.GetName() != "Limited Edition Alpha" || alpha.GetReleaseDate() != "1993-08-05" {
+		t.Errorf("GetSet() after UpsertSet() = %q released %q, want the name updated and the release date kept", alpha.GetName(), alpha.GetReleaseDate())
+	}
+
+	action, err := mtgjson.Set.UpsertSet(ctx, &setModel.Set{Code: "LEB", Name: "Limited Edition Beta"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if action != client.UpsertCreated {
+		t.Errorf("UpsertSet() = %s, want %s", action, client.UpsertCreated)
+	}
+
+	if _, err := mtgjson.Set.UpdateSet(ctx, nil, ""); !errors.Is(err, sdkErrors.ErrSetMissingId) {
+		t.Errorf("UpdateSet() with a nil set error = %v, want ErrSetMissingId", err)
+	}
+
+	if _, err := mtgjson.Set.UpsertSet(ctx, &setModel.Set{Name: "Limited Edition Alpha"}, ""); !errors.Is(err, sdkErrors.ErrSetMissingId) {
+		t.Errorf("UpsertSet() with a set without a code error = %v, want ErrSetMissingId", err)
+	}
+
+	if _, err := mtgjson.Set.NewSet(ctx, &setModel.Set{Code: "LEA", Name: "Limited Edition Alpha"}, ""); !errors.Is(err, sdkErrors.ErrSetAlreadyExists) {
+		t.Errorf("NewSet() with an existing code error = %v, want ErrSetAlreadyExists", err)
+	}
+
+	var codes []string
+	for set, err := range mtgjson.Set.IterSets(ctx, 1) {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		codes = append(codes, set.GetCode())
+	}
+
+	slices.Sort(codes)
+	if !slices.Equal(codes, []string{"LEA", "LEB"}) {
+		t.Errorf("IterSets() = %v, want [LEA LEB]", codes)
+	}
+
+	if _, err := mtgjson.Set.AddCards(ctx, "LEA", []string{counterspell}, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	var streamed []string
+	for card, err := range mtgjson.Set.StreamSetContents(ctx, "LEA", "") {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		streamed = append(streamed, card.GetIdentifiers().GetMtgjsonV4Id())
+	}
+
+	slices.Sort(streamed)
+	if !slices.Equal(streamed, []string{lightningBolt, counterspell}) {
+		t.Errorf("StreamSetContents() = %v, want both cards added to the set", streamed)
+	}
+
+	if _, err := mtgjson.Set.RemoveCards(ctx, "LEA", []string{lightningBolt}, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	contents, err := mtgjson.Set.GetSetContents(ctx, "LEA", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(*contents) != 1 || (*contents)[0].GetIdentifiers().GetMtgjsonV4Id() != counterspell {
+		t.Errorf("GetSetContents() after RemoveCards() returned %d cards, want only Counterspell", len(*contents))
+	}
+
+	if _, err := mtgjson.Set.DeleteSet(ctx, "LEB", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := mtgjson.Set.GetSet(ctx, "LEB", ""); !errors.Is(err, sdkErrors.ErrNoSet) {
+		t.Errorf("GetSet() after DeleteSet() error = %v, want ErrNoSet", err)
+	}
+}
@@ -0,0 +1,341 @@
+package testserver
+
+import (
+	"errors"
+	sdkErrors "github.com/stevezaluk/mtgjson-models/errors"
+	setModel "github.com/stevezaluk/mtgjson-models/set"
+	"net/http"
+	"slices"
+	"sort"
+)
+
+/*
+setRecord - A set held in the store of the server along with its owner and contents
+*/
+type setRecord struct {
+	// set - The stored set model
+	set *setModel.Set
+
+	// owner - The email address of the user that owns the set
+	owner string
+
+	// cards - The MTGJSONv4 IDs of the cards in the set
+	cards []string
+}
+
+/*
+storeSet - Insert a set into the store. Returns ErrSetMissingId if the set has no code and ErrSetAlreadyExists if a
+set already exists under the same code
+*/
+func (server *Server) storeSet(set *setModel.Set, owner string) error {
+	if err := validateSet(set); err != nil {
+		return err
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	if _, ok := server.sets[set.GetCode()]; ok {
+		return sdkErrors.ErrSetAlreadyExists
+	}
+
+	set.MtgjsonApiMeta = newMeta(owner)
+	server.sets[set.GetCode()] = &setRecord{set: set, owner: owner}
+
+	return nil
+}
+
+/*
+validateSet - Returns the error that the API responds with when the set passed in the parameter is created or replaced
+*/
+func validateSet(set *setModel.Set) error {
+	switch {
+	case set.GetMtgjsonApiMeta() != nil:
+		return sdkErrors.ErrMetaApiMustBeNull
+	case set.GetCode() == "" || set.GetName() == "":
+		return sdkErrors.ErrSetMissingId
+	}
+
+	return nil
+}
+
+/*
+lookupSet - Returns the record of the set stored under the code passed in the parameter that is visible to the owner.
+The caller must hold the mutex
+*/
+func (server *Server) lookupSet(code string, owner string) (*setRecord, error) {
+	if code == "" {
+		return nil, sdkErrors.ErrSetMissingId
+	}
+
+	record, ok := server.sets[code]
+	if !ok || !ownedBy(record.owner, owner) {
+		return nil, sdkErrors.ErrNoSet
+	}
+
+	return record, nil
+}
+
+/*
+addToSet - Append the cards whose MTGJSONv4 IDs are passed in the parameter to the set under the code
+*/
+func (server *Server) addToSet(code string, owner string, cards []string) error {
+	if len(cards) == 0 {
+		return sdkErrors.ErrSetNoCards
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	record, err := server.lookupSet(code, owner)
+	if err != nil {
+		return err
+	}
+
+	record.cards = append(record.cards, cards...)
+
+	return nil
+}
+
+/*
+getSet - GET /set. Returns the set under the setCode query parameter, or every set if it is not set
+*/
+func (server *Server) getSet(writer http.ResponseWriter, request *http.Request) {
+	code := request.URL.Query().Get("setCode")
+	owner := request.URL.Query().Get("owner")
+
+	server.mutex.RLock()
+	defer server.mutex.RUnlock()
+
+	if code == "" {
+		server.indexSets(writer, request)
+		return
+	}
+
+	record, err := server.lookupSet(code, owner)
+	if err != nil {
+		writeError(writer, errorStatus(err), err)
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, record.set)
+}
+
+/*
+indexSets - GET /set without a set code. Returns the page of sets visible to the owner selected by the limit and
+offset query parameters, ordered by set code. The caller must hold the mutex
+*/
+func (server *Server) indexSets(writer http.ResponseWriter, request *http.Request) {
+	owner := request.URL.Query().Get("owner")
+
+	limit, offset, ok := pageParams(request)
+	if !ok {
+		writeError(writer, http.StatusBadRequest, sdkErrors.ErrNoSets)
+		return
+	}
+
+	sets := make([]*setModel.Set, 0, len(server.sets))
+	for _, record := range server.sets {
+		if ownedBy(record.owner, owner) {
+			sets = append(sets, record.set)
+		}
+	}
+
+	sort.Slice(sets, func(i, j int) bool {
+		return sets[i].GetCode() < sets[j].GetCode()
+	})
+
+	sets = page(sets, limit, offset)
+	if len(sets) == 0 {
+		writeError(writer, http.StatusNotFound, sdkErrors.ErrNoSets)
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, sets)
+}
+
+/*
+newSet - POST /set. Inserts the set in the request body under the owner query parameter
+*/
+func (server *Server) newSet(writer http.ResponseWriter, request *http.Request) {
+	set, err := decodeBody[setModel.Set](request)
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	err = server.storeSet(set, ownerOrSystem(request.URL.Query().Get("owner")))
+	if err != nil {
+		writeError(writer, errorStatus(err), err)
+		return
+	}
+
+	writeMessage(writer, http.StatusCreated, "Successfully inserted new set")
+}
+
+/*
+putSet - PUT /set. Replaces the set under the setCode query parameter with the set in the request body, keeping its
+contents
+*/
+func (server *Server) putSet(writer http.ResponseWriter, request *http.Request) {
+	server.replaceSet(writer, request, func(current *setModel.Set) (*setModel.Set, error) {
+		return decodeBody[setModel.Set](request)
+	})
+}
+
+/*
+patchSet - PATCH /set. Applies the merge patch in the request body to the set under the setCode query parameter
+*/
+func (server *Server) patchSet(writer http.ResponseWriter, request *http.Request) {
+	server.replaceSet(writer, request, func(current *setModel.Set) (*setModel.Set, error) {
+		return mergePatch(current, request)
+	})
+}
+
+/*
+replaceSet - Shared implementation of PUT and PATCH /set. The build function is called with a copy of the stored set,
+without its API metadata, and returns the set that replaces it. The code of the set cannot be changed
+*/
+func (server *Server) replaceSet(writer http.ResponseWriter, request *http.Request, build func(current *setModel.Set) (*setModel.Set, error)) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	record, err := server.lookupSet(request.URL.Query().Get("setCode"), request.URL.Query().Get("owner"))
+	if err != nil {
+		writeError(writer, errorStatus(err), err)
+		return
+	}
+
+	if preconditionFailed(writer, request, record.set.GetMtgjsonApiMeta()) {
+		return
+	}
+
+	current := clone(record.set)
+	current.MtgjsonApiMeta = nil
+
+	set, err := build(current)
+	if err == nil && set.GetCode() != record.set.GetCode() {
+		err = sdkErrors.ErrSetMissingId
+	}
+
+	if err == nil {
+		err = validateSet(set)
+	}
+
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	set.MtgjsonApiMeta = touchMeta(record.set.GetMtgjsonApiMeta())
+	record.set = set
+
+	writeMessage(writer, http.StatusOK, "Successfully updated set")
+}
+
+/*
+deleteSet - DELETE /set. Removes the set under the setCode query parameter
+*/
+func (server *Server) deleteSet(writer http.ResponseWriter, request *http.Request) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	record, err := server.lookupSet(request.URL.Query().Get("setCode"), request.URL.Query().Get("owner"))
+	if errors.Is(err, sdkErrors.ErrSetMissingId) { // the API reports a missing code the same as an unknown one here
+		err = sdkErrors.ErrNoSet
+	}
+
+	if err != nil {
+		writeError(writer, errorStatus(err), err)
+		return
+	}
+
+	delete(server.sets, record.set.GetCode())
+
+	writeMessage(writer, http.StatusOK, "Successfully deleted set")
+}
+
+/*
+getSetContents - GET /set/content. Returns the cards in the set under the setCode query parameter
+*/
+func (server *Server) getSetContents(writer http.ResponseWriter, request *http.Request) {
+	server.mutex.RLock()
+	defer server.mutex.RUnlock()
+
+	record, err := server.lookupSet(request.URL.Query().Get("setCode"), request.URL.Query().Get("owner"))
+	if err != nil {
+		writeError(writer, errorStatus(err), err)
+		return
+	}
+
+	if len(record.cards) == 0 {
+		writeError(writer, http.StatusBadRequest, sdkErrors.ErrNoCards)
+		return
+	}
+
+	cards, err := server.resolveCards(record.cards)
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, sdkErrors.ErrNoCards)
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, cards)
+}
+
+/*
+addSetCards - POST /set/content. Appends the MTGJSONv4 IDs in the request body to the set
+*/
+func (server *Server) addSetCards(writer http.ResponseWriter, request *http.Request) {
+	cards, err := decodeBody[[]string](request)
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, sdkErrors.ErrSetNoCards)
+		return
+	}
+
+	err = server.addToSet(request.URL.Query().Get("setCode"), request.URL.Query().Get("owner"), *cards)
+	if err != nil {
+		writeError(writer, errorStatus(err), err)
+		return
+	}
+
+	writeMessage(writer, http.StatusOK, "Successfully updated set contents")
+}
+
+/*
+removeSetCards - DELETE /set/content. Removes every instance of the MTGJSONv4 IDs in the request body from the set.
+Returns ErrInvalidCards if any of them are not in the set
+*/
+func (server *Server) removeSetCards(writer http.ResponseWriter, request *http.Request) {
+	cards, err := decodeBody[[]string](request)
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	if len(*cards) == 0 {
+		writeError(writer, http.StatusBadRequest, sdkErrors.ErrSetNoCards)
+		return
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	record, err := server.lookupSet(request.URL.Query().Get("setCode"), request.URL.Query().Get("owner"))
+	if err != nil {
+		writeError(writer, errorStatus(err), err)
+		return
+	}
+
+	for _, uuid := range *cards {
+		if !slices.Contains(record.cards, uuid) {
+			writeError(writer, http.StatusBadRequest, sdkErrors.ErrInvalidCards)
+			return
+		}
+	}
+
+	record.cards = slices.DeleteFunc(record.cards, func(uuid string) bool {
+		return slices.Contains(*cards, uuid)
+	})
+
+	writeMessage(writer, http.StatusOK, "Successfully updated set contents")
+}
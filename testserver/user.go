@@ -0,0 +1,72 @@
+package testserver
+
+import (
+	sdkErrors "github.com/stevezaluk/mtgjson-models/errors"
+	"net/http"
+	"net/mail"
+)
+
+/*
+lookupUser - Returns the record of the user under the email query parameter of the request. The caller must hold the
+mutex
+*/
+func (server *Server) lookupUser(request *http.Request) (*userRecord, error) {
+	email := request.URL.Query().Get("email")
+	if _, err := mail.ParseAddress(email); err != nil {
+		return nil, sdkErrors.ErrInvalidEmail
+	}
+
+	record, ok := server.users[email]
+	if !ok {
+		return nil, sdkErrors.ErrNoUser
+	}
+
+	return record, nil
+}
+
+/*
+getUser - GET /user. Returns the user under the email query parameter
+*/
+func (server *Server) getUser(writer http.ResponseWriter, request *http.Request) {
+	server.mutex.RLock()
+	defer server.mutex.RUnlock()
+
+	record, err := server.lookupUser(request)
+	if err != nil {
+		writeError(writer, errorStatus(err), err)
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, record.user)
+}
+
+/*
+deactivateUser - DELETE /user. Removes the user under the email query parameter and revokes every token issued to them
+*/
+func (server *Server) deactivateUser(writer http.ResponseWriter, request *http.Request) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	record, err := server.lookupUser(request)
+	if err != nil {
+		writeError(writer, errorStatus(err), err)
+		return
+	}
+
+	email := record.user.GetEmail()
+	delete(server.users, email)
+
+	for token, issued := range server.accessTokens {
+		if issued.email == email {
+			delete(server.accessTokens, token)
+		}
+	}
+
+	for token, owner := range server.refreshTokens {
+		if owner == email {
+			delete(server.refreshTokens, token)
+		}
+	}
+
+	writeMessage(writer, http.StatusOK, "Successfully deactivated user")
+}
@@ -1,6 +1,7 @@
 package user
 
 import (
+	"context"
 	apiModels "github.com/stevezaluk/mtgjson-models/api"
 	sdkErrors "github.com/stevezaluk/mtgjson-models/errors"
 	userModel "github.com/stevezaluk/mtgjson-models/user"
@@ -20,13 +21,19 @@ type UserAPI struct {
 }
 
 /*
-New Create a new instance of the UserAPI struct
+New Create a new instance of the UserAPI struct. The base URL must be an absolute http(s) URL and may
+contain a path prefix, client.ErrInvalidBaseURL is returned if it is malformed
 */
-func New(baseUrl string, client *client.HTTPClient) *UserAPI {
-	return &UserAPI{
-		baseUrl: baseUrl + "/user",
-		client:  client,
+func New(baseUrl string, httpClient *client.HTTPClient) (*UserAPI, error) {
+	endpoint, err := client.JoinURL(baseUrl, "user")
+	if err != nil {
+		return nil, err
 	}
+
+	return &UserAPI{
+		baseUrl: endpoint,
+		client:  httpClient,
+	}, nil
 }
 
 /*
@@ -43,65 +50,29 @@ func (api *UserAPI) Client() *client.HTTPClient {
 	return api.client
 }
 
+/*
+userErrors - Maps the status codes returned from GET and DELETE /user to sentinel errors
+*/
+var userErrors = client.ErrorMap{
+	http.StatusNotFound:   {Default: sdkErrors.ErrNoUser},
+	http.StatusBadRequest: {Default: sdkErrors.ErrInvalidEmail},
+}
+
 /*
 GetUser Fetch a user based on their email address. Returns ErrNoUser if the user cannot be found
 and ErrInvalidEmail if an empty string or invalid email address is passed in the parameter
 */
-func (api *UserAPI) GetUser(email string) (*userModel.User, error) {
-	request := api.client.BuildRequest(&userModel.User{}).SetQueryParam("email", email)
-
-	resp, err := request.Get(api.baseUrl)
-	if err != nil {
-		return nil, err
-	}
-
-	if resp.StatusCode() == http.StatusUnauthorized {
-		return nil, sdkErrors.ErrTokenInvalid
-	}
-
-	if resp.StatusCode() == http.StatusForbidden {
-		return nil, sdkErrors.ErrInvalidPermissions
-	}
+func (api *UserAPI) GetUser(ctx context.Context, email string) (*userModel.User, error) {
+	request := api.client.BuildRequest(ctx).SetQueryParam("email", email)
 
-	if resp.StatusCode() == http.StatusNotFound {
-		return nil, sdkErrors.ErrNoUser
-	}
-
-	if resp.StatusCode() == http.StatusBadRequest {
-		return nil, sdkErrors.ErrInvalidEmail
-	}
-
-	return resp.Result().(*userModel.User), nil
+	return client.Execute[userModel.User](request, http.MethodGet, api.baseUrl, userErrors)
 }
 
 /*
 DeactivateUser Completely removes the requested user account, both from Auth0 and from MongoDB
 */
-func (api *UserAPI) DeactivateUser(email string) (*apiModels.APIResponse, error) {
-	request := api.client.BuildRequest(&apiModels.APIResponse{}).SetQueryParam("email", email)
-
-	resp, err := request.Delete(api.baseUrl)
-	if err != nil {
-		return nil, err
-	}
-
-	errResult := resp.Error().(*apiModels.APIResponse)
-
-	if resp.StatusCode() == http.StatusUnauthorized {
-		return errResult, sdkErrors.ErrTokenInvalid
-	}
-
-	if resp.StatusCode() == http.StatusForbidden {
-		return errResult, sdkErrors.ErrInvalidPermissions
-	}
-
-	if resp.StatusCode() == http.StatusNotFound {
-		return errResult, sdkErrors.ErrNoUser
-	}
-
-	if resp.StatusCode() == http.StatusBadRequest {
-		return errResult, sdkErrors.ErrInvalidEmail
-	}
+func (api *UserAPI) DeactivateUser(ctx context.Context, email string) (*apiModels.APIResponse, error) {
+	request := api.client.BuildRequest(ctx).SetQueryParam("email", email)
 
-	return resp.Result().(*apiModels.APIResponse), nil
+	return client.Execute[apiModels.APIResponse](request, http.MethodDelete, api.baseUrl, userErrors)
 }